@@ -0,0 +1,93 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	autoStartListKey = "autostart"
+)
+
+var (
+	// ErrAutoStartNotReported is an error signifying that the control command does not report the autostart setting of
+	// the instance (e.g. the version of the control command predates it)
+	ErrAutoStartNotReported = errors.New("control command does not report the autostart setting")
+)
+
+// AutoStart will determine whether the instance is configured to start automatically when the system boots.
+// The setting is read from the instance's configuration as reported by the control command's list subcommand.
+// It returns whether the instance starts automatically and any error encountered.
+func (i *Instance) AutoStart() (bool, error) {
+	settings, err := i.listSettings()
+	if err != nil {
+		return false, err
+	}
+
+	v, ok := settings[autoStartListKey]
+	if !ok {
+		return false, ErrAutoStartNotReported
+	}
+
+	switch strings.ToLower(v) {
+	case "1", "on", "yes", "true", "enabled":
+		return true, nil
+	case "0", "off", "no", "false", "disabled":
+		return false, nil
+	default:
+		return false, fmt.Errorf("unrecognized autostart setting: %s", v)
+	}
+}
+
+// SetAutoStart will configure whether the instance starts automatically when the system boots using the control
+// command's autostart subcommand.  The command is run as the instance manager when possible.
+// It returns any error encountered.
+func (i *Instance) SetAutoStart(enabled bool) error {
+	setting := "off"
+	if enabled {
+		setting = "on"
+	}
+
+	if _, err := i.RunControlCommand(autoStartListKey, setting); err != nil {
+		return fmt.Errorf("error setting autostart %s: %w", setting, err)
+	}
+
+	return nil
+}
+
+// listSettings runs the control command's list subcommand for the instance and parses its "key: value" lines.
+// Keys are lower cased and values have their surrounding whitespace removed.
+func (i *Instance) listSettings() (map[string]string, error) {
+	out, err := i.RunControlCommand("list")
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		if k, v, ok := strings.Cut(scanner.Text(), ":"); ok {
+			settings[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+		}
+	}
+
+	return settings, scanner.Err()
+}
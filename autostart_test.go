@@ -0,0 +1,92 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("SetAutoStart", func() {
+	var (
+		instance *Instance
+		argsFile string
+	)
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		argsFile = filepath.Join(dir, "args")
+		script := filepath.Join(dir, "control")
+		Expect(os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\n"), 0755)).To(Succeed())
+		instance = &Instance{Name: "INSTTEST", ControlPath: script}
+	})
+
+	It("Enables autostart", func() {
+		Expect(instance.SetAutoStart(true)).To(Succeed())
+		Expect(os.ReadFile(argsFile)).To(BeEquivalentTo("autostart INSTTEST on\n"))
+	})
+
+	It("Disables autostart", func() {
+		Expect(instance.SetAutoStart(false)).To(Succeed())
+		Expect(os.ReadFile(argsFile)).To(BeEquivalentTo("autostart INSTTEST off\n"))
+	})
+
+	It("Returns an error when the command fails", func() {
+		instance.ControlPath = filepath.Join(GinkgoT().TempDir(), "missing")
+		Expect(instance.SetAutoStart(true)).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("AutoStart", func() {
+	const listOutput = `Configuration 'INSTTEST'   (default)
+	directory:    /usr/irissys
+	versionid:    2023.1.0.235.1
+	datadir:      /usr/irissys
+	conf file:    iris.cpf  (SuperServer port = 1972, WebServer = 52773)
+	status:       running, since Tue Jun  6 14:06:01 2023
+	state:        ok
+	product:      InterSystems IRIS
+%s`
+	var instance *Instance
+
+	writeControl := func(extra string) {
+		script := filepath.Join(GinkgoT().TempDir(), "control")
+		Expect(os.WriteFile(script, []byte("#!/bin/sh\ncat <<'EOF'\n"+fmt.Sprintf(listOutput, extra)+"\nEOF\n"), 0755)).To(Succeed())
+		instance = &Instance{Name: "INSTTEST", ControlPath: script}
+	}
+
+	It("Reports an enabled autostart", func() {
+		writeControl("\tautostart:    yes")
+		Expect(instance.AutoStart()).To(BeTrue())
+	})
+
+	It("Reports a disabled autostart", func() {
+		writeControl("\tautostart:    no")
+		Expect(instance.AutoStart()).To(BeFalse())
+	})
+
+	It("Returns an error when the setting is not reported", func() {
+		writeControl("")
+		_, err := instance.AutoStart()
+		Expect(err).To(MatchError(ErrAutoStartNotReported))
+	})
+})
@@ -17,8 +17,14 @@ limitations under the License.
 package isclib
 
 import (
+	"errors"
 	"fmt"
 	"os/user"
+	"slices"
+)
+
+var (
+	currentUser = user.Current
 )
 
 func checkUser(username string) error {
@@ -38,3 +44,72 @@ func checkUser(username string) error {
 
 	return nil
 }
+
+// IsManager will determine whether the current user manages the instance, either by being the instance's manager user
+// or a member of its manager group (see DetermineManager).
+// It returns whether the current user manages the instance and any error encountered.
+func (i *Instance) IsManager() (bool, error) {
+	mgr, group, err := i.DetermineManager()
+	if err != nil {
+		return false, err
+	}
+
+	return currentUserIs(mgr, group)
+}
+
+// IsOwner will determine whether the current user is the user the instance runs as (see DetermineOwner).
+// It returns whether the current user owns the instance and any error encountered.
+func (i *Instance) IsOwner() (bool, error) {
+	owner, _, err := i.DetermineOwner()
+	if err != nil {
+		return false, err
+	}
+
+	return currentUserIs(owner, "")
+}
+
+// currentUserIs reports whether the current user is the named user or, if a group is provided, a member of the group
+func currentUserIs(username, group string) (bool, error) {
+	cur, err := currentUser()
+	if err != nil {
+		return false, err
+	}
+
+	if cur.Username == username {
+		return true, nil
+	}
+
+	if group == "" {
+		return false, nil
+	}
+
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		var unknown user.UnknownGroupError
+		if errors.As(err, &unknown) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if cur.Gid == g.Gid {
+		return true, nil
+	}
+
+	gids, err := cur.GroupIds()
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(gids, g.Gid), nil
+}
+
+// isRoot reports whether the current user is root
+func isRoot() (bool, error) {
+	cur, err := currentUser()
+	if err != nil {
+		return false, err
+	}
+
+	return cur.Uid == "0", nil
+}
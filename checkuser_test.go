@@ -0,0 +1,96 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/user"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manageable instances", func() {
+	// parameters keyed by instance directory, a missing directory has no parameters.isc
+	parameters := map[string]string{
+		"/managed":    "security_settings.manager_user: alice\nsecurity_settings.manager_group: nobodygrp\nsecurity_settings.iris_user: irisowner\nsecurity_settings.iris_group: irisusr\n",
+		"/owned":      "security_settings.manager_user: bob\nsecurity_settings.manager_group: nobodygrp\nsecurity_settings.iris_user: alice\nsecurity_settings.iris_group: irisusr\n",
+		"/group":      "security_settings.manager_user: bob\nsecurity_settings.manager_group: root\nsecurity_settings.iris_user: irisowner\nsecurity_settings.iris_group: irisusr\n",
+		"/unrelated":  "security_settings.manager_user: bob\nsecurity_settings.manager_group: nobodygrp\nsecurity_settings.iris_user: irisowner\nsecurity_settings.iris_group: irisusr\n",
+		"/incomplete": "security_settings.iris_user: alice\nsecurity_settings.iris_group: irisusr\n",
+	}
+	var (
+		instances Instances
+		alice     *user.User
+	)
+
+	BeforeEach(func() {
+		parameterReader = func(directory string, file string) (io.ReadCloser, error) {
+			p, ok := parameters[directory]
+			if !ok {
+				return nil, os.ErrNotExist
+			}
+			return io.NopCloser(bytes.NewBufferString(p)), nil
+		}
+		alice = &user.User{Username: "alice", Uid: "1000", Gid: "1000"}
+		currentUser = func() (*user.User, error) {
+			return alice, nil
+		}
+		instances = Instances{
+			{Name: "MANAGED", Directory: "/managed", Product: Iris},
+			{Name: "OWNED", Directory: "/owned", Product: Iris},
+			{Name: "GROUP", Directory: "/group", Product: Iris},
+			{Name: "UNRELATED", Directory: "/unrelated", Product: Iris},
+			{Name: "UNREADABLE", Directory: "/unreadable", Product: Iris},
+			{Name: "INCOMPLETE", Directory: "/incomplete", Product: Iris},
+		}
+	})
+	AfterEach(func() {
+		parameterReader = fileParameterReader
+		currentUser = user.Current
+	})
+
+	It("Reports whether the current user is the manager", func() {
+		Expect(instances[0].IsManager()).To(BeTrue())
+		Expect(instances[1].IsManager()).To(BeFalse())
+	})
+
+	It("Reports whether the current user is the owner", func() {
+		Expect(instances[0].IsOwner()).To(BeFalse())
+		Expect(instances[1].IsOwner()).To(BeTrue())
+	})
+
+	It("Returns the instances the current user manages or owns", func() {
+		manageable, err := instances.manageable()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manageable).To(Equal(Instances{instances[0], instances[1]}))
+	})
+
+	It("Includes instances managed by the current user's group", func() {
+		alice.Gid = "0"
+		manageable, err := instances.manageable()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manageable).To(Equal(Instances{instances[0], instances[1], instances[2]}))
+	})
+
+	It("Returns every instance for root", func() {
+		alice.Uid = "0"
+		Expect(instances.manageable()).To(Equal(instances))
+	})
+})
@@ -17,6 +17,7 @@ limitations under the License.
 package isclib
 
 import (
+	"os"
 	"os/exec"
 
 	log "github.com/sirupsen/logrus"
@@ -49,19 +50,19 @@ const (
 func AvailableCommands() Commands {
 	var commands = NoCommand
 
-	if _, err := exec.LookPath(globalIrisPath); err == nil {
+	if _, err := exec.LookPath(os.ExpandEnv(globalIrisPath)); err == nil {
 		commands.Set(IrisCommand)
 	} else {
 		log.WithField("irisPath", globalIrisPath).WithError(err).Debug("iris executable not found")
 	}
 
-	if _, err := exec.LookPath(globalCControlPath); err == nil {
+	if _, err := exec.LookPath(os.ExpandEnv(globalCControlPath)); err == nil {
 		commands.Set(CControlCommand)
 	} else {
 		log.WithField("controlPath", globalCControlPath).WithError(err).Debug("ccontrol executable not found")
 	}
 
-	if _, err := exec.LookPath(globalCSessionPath); err == nil {
+	if _, err := exec.LookPath(os.ExpandEnv(globalCSessionPath)); err == nil {
 		commands.Set(CSessionCommand)
 	} else {
 		log.WithField("csessionPath", globalCControlPath).WithError(err).Debug("csession executable not found")
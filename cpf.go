@@ -0,0 +1,474 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CPF represents the contents of an ISC configuration parameter file
+type CPF struct {
+	// The sections of the CPF in the order they appear in the file
+	Sections []*CPFSection
+
+	// The lines before the first section
+	preamble []string
+	// The lines of each parsed section so the file can be written back out unchanged (see WriteTo)
+	layouts map[*CPFSection]*cpfLayout
+}
+
+// cpfLayout is the original text of a parsed section
+type cpfLayout struct {
+	header string
+	lines  []cpfLine
+}
+
+// cpfLine is a single line of a parsed section along with the entry it held, if any
+type cpfLine struct {
+	text string
+	// The index of the entry in the section, -1 if the line is not an entry
+	entry int
+	// The original key and value of the entry so unchanged entries are written as they were read
+	key, value string
+}
+
+// CPFSection represents a single [section] of a CPF
+type CPFSection struct {
+	// The name of the section (without the brackets)
+	Name string
+
+	// The key/value pairs of the section in the order they appear in the file
+	Entries []CPFEntry
+
+	// The directives of the section in the order they appear in the file.
+	// Directives are only found in the [Actions] section of merge files.
+	Directives []CPFDirective
+}
+
+// CPFDirective represents a single Action:arguments line from the [Actions] section of a merge CPF
+// (e.g. CreateDatabase:Name=APP,Directory=/data/app).  Unlike entries, directives may be repeated.
+type CPFDirective struct {
+	// The name of the action (the portion of the line before the :)
+	Action string
+
+	// The comma separated arguments of the action in the order they appear
+	Arguments []string
+}
+
+// CPFEntry represents a single key=value line of a CPF section
+type CPFEntry struct {
+	Key   string
+	Value string
+}
+
+// CPFFieldDiff represents a single key which differs between two CPFs
+type CPFFieldDiff struct {
+	Section string
+	Key     string
+	A       string // The value in the first CPF
+	B       string // The value in the second CPF
+	InA     bool   // Whether the key exists in the first CPF
+	InB     bool   // Whether the key exists in the second CPF
+}
+
+// ParseCPF will parse the CPF contained in the provided reader.
+// Every line of the file, including blank lines and lines which are neither entries nor directives, is retained so
+// the CPF can be written back out unchanged (see WriteTo).  Entries which appear before the first section are placed
+// in a section with an empty name.
+// It returns the CPF data structure and any error encountered
+func ParseCPF(r io.Reader) (*CPF, error) {
+	cpf := &CPF{layouts: make(map[*CPFSection]*cpfLayout)}
+	var section *CPFSection
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		text := scanner.Text()
+		line := strings.TrimSpace(text)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = cpf.addSection(line[1:len(line)-1], text)
+			continue
+		}
+
+		if section == nil {
+			if _, _, ok := strings.Cut(line, "="); !ok {
+				cpf.preamble = append(cpf.preamble, text)
+				continue
+			}
+			section = cpf.addSection("", "")
+		}
+
+		layout := cpf.layouts[section]
+		if line != "" && section.isDirectiveSection() {
+			if d, ok := parseCPFDirective(line); ok {
+				section.Directives = append(section.Directives, d)
+				layout.lines = append(layout.lines, cpfLine{text: text, entry: -1})
+				continue
+			}
+		}
+
+		if key, value, ok := strings.Cut(line, "="); ok && line != "" {
+			layout.lines = append(layout.lines, cpfLine{text: text, entry: len(section.Entries), key: key, value: value})
+			section.Entries = append(section.Entries, CPFEntry{Key: key, Value: value})
+			continue
+		}
+
+		layout.lines = append(layout.lines, cpfLine{text: text, entry: -1})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return cpf, nil
+}
+
+func (c *CPF) addSection(name, header string) *CPFSection {
+	s := &CPFSection{Name: name}
+	c.Sections = append(c.Sections, s)
+	c.layouts[s] = &cpfLayout{header: header}
+	return s
+}
+
+// Set ensures the key in the named section is set to value.
+// Every occurrence of an existing key is replaced.  A missing key is added to the end of the section and a missing
+// section is added to the end of the CPF.
+func (c *CPF) Set(section, key, value string) {
+	s := c.section(section)
+	if s == nil {
+		s = &CPFSection{Name: section}
+		c.Sections = append(c.Sections, s)
+	}
+
+	replaced := false
+	for n := range s.Entries {
+		if s.Entries[n].Key == key {
+			s.Entries[n].Value = value
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		s.Entries = append(s.Entries, CPFEntry{Key: key, Value: value})
+	}
+}
+
+// WriteTo writes the CPF to the provided writer.
+// A parsed CPF is written exactly as it was read, other than the entries which have been changed or added since,
+// which keeps the ordering and any lines which are not entries.  Added entries are written after the last line of
+// their section and added sections are written at the end, separated by a blank line.
+// It returns the number of bytes written and any error encountered.
+func (c *CPF) WriteTo(w io.Writer) (int64, error) {
+	cw := &cpfWriter{w: w, blank: true}
+	for _, line := range c.preamble {
+		cw.line(line)
+	}
+
+	for _, s := range c.Sections {
+		layout := c.layouts[s]
+		if layout == nil {
+			if !cw.blank {
+				cw.line("")
+			}
+			if s.Name != "" {
+				cw.line("[" + s.Name + "]")
+			}
+			for _, d := range s.Directives {
+				cw.line(d.String())
+			}
+			for _, e := range s.Entries {
+				cw.line(e.Key + "=" + e.Value)
+			}
+			continue
+		}
+
+		if layout.header != "" {
+			cw.line(layout.header)
+		}
+
+		written := make(map[int]bool)
+		last := -1
+		for n, line := range layout.lines {
+			if line.entry >= 0 {
+				written[line.entry] = true
+			}
+			if strings.TrimSpace(line.text) != "" {
+				last = n
+			}
+		}
+
+		if last < 0 {
+			cw.added(s, written)
+		}
+
+		for n, line := range layout.lines {
+			if line.entry < 0 {
+				cw.line(line.text)
+			} else if line.entry < len(s.Entries) {
+				if e := s.Entries[line.entry]; e.Key == line.key && e.Value == line.value {
+					cw.line(line.text)
+				} else {
+					cw.line(e.Key + "=" + e.Value)
+				}
+			}
+
+			if n == last {
+				cw.added(s, written)
+			}
+		}
+	}
+
+	return cw.n, cw.err
+}
+
+// cpfWriter writes lines while tracking the bytes written and the first error encountered
+type cpfWriter struct {
+	w     io.Writer
+	n     int64
+	err   error
+	blank bool // whether the last line written was blank
+}
+
+func (cw *cpfWriter) line(line string) {
+	if cw.err != nil {
+		return
+	}
+
+	n, err := io.WriteString(cw.w, line+"\n")
+	cw.n += int64(n)
+	cw.err = err
+	cw.blank = strings.TrimSpace(line) == ""
+}
+
+// added writes the entries of the section which do not appear in its original lines
+func (cw *cpfWriter) added(s *CPFSection, written map[int]bool) {
+	for n, e := range s.Entries {
+		if !written[n] {
+			cw.line(e.Key + "=" + e.Value)
+		}
+	}
+}
+
+// Section returns the key/value pairs of the named section.
+// If a key is repeated, the last value is used.
+// It returns an empty map if the section does not exist.
+func (c *CPF) Section(name string) map[string]string {
+	values := make(map[string]string)
+	if s := c.section(name); s != nil {
+		for _, e := range s.Entries {
+			values[e.Key] = e.Value
+		}
+	}
+
+	return values
+}
+
+// Directives returns the directives of the named section (e.g. Actions).
+// It returns an empty slice if the section does not exist or has no directives.
+func (c *CPF) Directives(section string) []CPFDirective {
+	if s := c.section(section); s != nil && s.Directives != nil {
+		return s.Directives
+	}
+
+	return []CPFDirective{}
+}
+
+// Argument returns the value of the named key=value argument and whether it exists
+func (d CPFDirective) Argument(name string) (string, bool) {
+	for _, a := range d.Arguments {
+		if k, v, ok := strings.Cut(a, "="); ok && k == name {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+// String returns the directive as it appears in a CPF
+func (d CPFDirective) String() string {
+	return d.Action + ":" + strings.Join(d.Arguments, ",")
+}
+
+func (s *CPFSection) isDirectiveSection() bool {
+	return strings.EqualFold(s.Name, "Actions")
+}
+
+func parseCPFDirective(line string) (CPFDirective, bool) {
+	action, args, ok := strings.Cut(line, ":")
+	// a directive's action never contains an = so anything before one is a key/value line
+	if !ok || action == "" || strings.Contains(action, "=") {
+		return CPFDirective{}, false
+	}
+
+	d := CPFDirective{Action: action, Arguments: []string{}}
+	if args != "" {
+		d.Arguments = strings.Split(args, ",")
+	}
+
+	return d, true
+}
+
+// Value returns the value of the key in the named section and whether it exists
+func (c *CPF) Value(section, key string) (string, bool) {
+	v, ok := c.Section(section)[key]
+	return v, ok
+}
+
+func (c *CPF) section(name string) *CPFSection {
+	for _, s := range c.Sections {
+		if s.Name == name {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// CPFDiff compares two CPFs and reports every section/key whose value differs or which only exists in one of them.
+// The differences are ordered by the position of the section and key in a, followed by those only in b.
+func CPFDiff(a, b *CPF) []CPFFieldDiff {
+	diffs := make([]CPFFieldDiff, 0)
+	for _, name := range cpfSectionNames(a, b) {
+		av := a.Section(name)
+		bv := b.Section(name)
+		for _, key := range cpfKeys(a.section(name), b.section(name)) {
+			aValue, inA := av[key]
+			bValue, inB := bv[key]
+			if inA == inB && aValue == bValue {
+				continue
+			}
+			diffs = append(diffs, CPFFieldDiff{Section: name, Key: key, A: aValue, B: bValue, InA: inA, InB: inB})
+		}
+	}
+
+	return diffs
+}
+
+func cpfSectionNames(cpfs ...*CPF) []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, cpf := range cpfs {
+		for _, s := range cpf.Sections {
+			if !seen[s.Name] {
+				seen[s.Name] = true
+				names = append(names, s.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+func cpfKeys(sections ...*CPFSection) []string {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	for _, s := range sections {
+		if s == nil {
+			continue
+		}
+		for _, e := range s.Entries {
+			if !seen[e.Key] {
+				seen[e.Key] = true
+				keys = append(keys, e.Key)
+			}
+		}
+	}
+
+	return keys
+}
+
+// LoadCPF will read and parse the instance's CPF file.
+// It returns the CPF data structure and any error encountered.
+func (i *Instance) LoadCPF() (*CPF, error) {
+	file, err := os.Open(i.CPFFilePath())
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseCPF(file)
+}
+
+// CPFDiff will load the CPF files of this instance and the other instance and compare them (see CPFDiff).
+// It returns the differences and any error encountered.
+func (i *Instance) CPFDiff(other *Instance) ([]CPFFieldDiff, error) {
+	a, err := i.LoadCPF()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := other.LoadCPF()
+	if err != nil {
+		return nil, err
+	}
+
+	return CPFDiff(a, b), nil
+}
+
+// SetCPFValue ensures that the cpf file at the path provided has the key in the named section set to value.
+// The key is added to the end of the section if it does not exist and the section is added to the end of the file if
+// it does not exist.  All other lines of the file are left untouched.
+// The file is replaced atomically preserving its permissions and ownership (see ToggleZSTU).
+// It returns any error encountered.
+func SetCPFValue(cpfFilePath, section, key, value string) error {
+	return rewriteCPF(cpfFilePath, func(r io.Reader, w io.Writer) error {
+		return setCPFValue(r, w, section, key, value)
+	})
+}
+
+func setCPFValue(r io.Reader, w io.Writer, section, key, value string) error {
+	cpf, err := ParseCPF(r)
+	if err != nil {
+		return err
+	}
+
+	cpf.Set(section, key, value)
+	_, err = cpf.WriteTo(w)
+	return err
+}
+
+// SetCPFValue ensures that the instance's CPF file (see CPFFilePath) has the key in the named section set to value.
+// Changes to the CPF do not take effect until they are activated (see ActivateConfig) or the instance is restarted.
+// It returns any error encountered.
+func (i *Instance) SetCPFValue(section, key, value string) error {
+	return SetCPFValue(i.CPFFilePath(), section, key, value)
+}
+
+// SetSuperServerPort sets the SuperServer port ([Startup] DefaultPort) in the instance's CPF file.
+// The new port is used once the instance is restarted, at which point Update will report it in SuperServerPort.
+// It returns any error encountered.
+func (i *Instance) SetSuperServerPort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("invalid SuperServer port: %d", port)
+	}
+
+	return i.SetCPFValue("Startup", "DefaultPort", strconv.Itoa(port))
+}
+
+// cpfSection reads the key/value pairs of a single section from the instance's CPF file
+func (i *Instance) cpfSection(section string) (map[string]string, error) {
+	cpf, err := i.LoadCPF()
+	if err != nil {
+		return nil, err
+	}
+
+	return cpf.Section(section), nil
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/ontariosystems/isclib/v2"
+	"github.com/spf13/afero"
+)
+
+const testCPF = `[ConfigFile]
+Product=IRIS
+Version=2022.1
+
+[Databases]
+IRISSYS=/usr/irissys/mgr/
+USER=/usr/irissys/mgr/user/
+
+[Startup]
+DefaultPort=1972
+ShutdownTimeout=300
+`
+
+var _ = Describe("CPF", func() {
+	Context("ParseCPF", func() {
+		It("Returns an error from a failing reader", func() {
+			_, err := isclib.ParseCPF(new(failReader))
+			Expect(err).To(MatchError("Blam!"))
+		})
+
+		It("Parses the sections and entries in order", func() {
+			cpf, err := isclib.ParseCPF(bytes.NewBufferString(testCPF))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cpf.Sections).To(HaveLen(3))
+			Expect(cpf.Sections[1]).To(Equal(&isclib.CPFSection{Name: "Databases", Entries: []isclib.CPFEntry{
+				{Key: "IRISSYS", Value: "/usr/irissys/mgr/"},
+				{Key: "USER", Value: "/usr/irissys/mgr/user/"},
+			}}))
+		})
+
+		It("Looks up sections and values", func() {
+			cpf, err := isclib.ParseCPF(bytes.NewBufferString(testCPF))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cpf.Section("Startup")).To(Equal(map[string]string{"DefaultPort": "1972", "ShutdownTimeout": "300"}))
+			Expect(cpf.Section("Missing")).To(BeEmpty())
+			v, ok := cpf.Value("ConfigFile", "Product")
+			Expect(ok).To(BeTrue())
+			Expect(v).To(Equal("IRIS"))
+			_, ok = cpf.Value("ConfigFile", "Missing")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("WriteTo", func() {
+		const irregularCPF = `; written by hand
+[ConfigFile]
+Product=IRIS
+  Version=2022.1
+this line is not an entry
+
+[Actions]
+CreateDatabase:Name=APP,Directory=/data/app
+
+[Databases]
+APP=/data/app1/,1,,,
+[Startup]
+DefaultPort=1972
+`
+		write := func(cpf *isclib.CPF) string {
+			var b bytes.Buffer
+			n, err := cpf.WriteTo(&b)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(BeEquivalentTo(b.Len()))
+			return b.String()
+		}
+
+		It("Round trips the file unchanged", func() {
+			for _, contents := range []string{testCPF, irregularCPF} {
+				cpf, err := isclib.ParseCPF(bytes.NewBufferString(contents))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(write(cpf)).To(Equal(contents))
+			}
+		})
+
+		It("Writes changed and added values in place", func() {
+			cpf, err := isclib.ParseCPF(bytes.NewBufferString(irregularCPF))
+			Expect(err).NotTo(HaveOccurred())
+			cpf.Set("ConfigFile", "Version", "2023.1")
+			cpf.Set("Databases", "USER", "/data/user/")
+			cpf.Set("Journal", "FileSizeLimit", "1024")
+			Expect(write(cpf)).To(Equal(`; written by hand
+[ConfigFile]
+Product=IRIS
+Version=2023.1
+this line is not an entry
+
+[Actions]
+CreateDatabase:Name=APP,Directory=/data/app
+
+[Databases]
+APP=/data/app1/,1,,,
+USER=/data/user/
+[Startup]
+DefaultPort=1972
+
+[Journal]
+FileSizeLimit=1024
+`))
+		})
+
+		It("Writes a CPF which was not parsed", func() {
+			cpf := &isclib.CPF{Sections: []*isclib.CPFSection{
+				{Name: "Actions", Directives: []isclib.CPFDirective{{Action: "Reload", Arguments: []string{}}}},
+				{Name: "Startup", Entries: []isclib.CPFEntry{{Key: "DefaultPort", Value: "1972"}}},
+			}}
+			Expect(write(cpf)).To(Equal("[Actions]\nReload:\n\n[Startup]\nDefaultPort=1972\n"))
+		})
+
+		It("Keeps entries which precede the first section", func() {
+			cpf, err := isclib.ParseCPF(bytes.NewBufferString("ZSTU=1\n[Startup]\nDefaultPort=1972\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cpf.Section("")).To(Equal(map[string]string{"ZSTU": "1"}))
+			cpf.Set("", "ZSTU", "0")
+			Expect(write(cpf)).To(Equal("ZSTU=0\n[Startup]\nDefaultPort=1972\n"))
+		})
+	})
+
+	Context("Instance CPF consumers", func() {
+		var instance *isclib.Instance
+
+		BeforeEach(func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(dir, "iris.cpf"), []byte(`[Databases]
+APP1=/data/app1/,1,,,
+USER=/data/user/
+
+[Journal]
+AlternateDirectory=/journal/alternate/
+CurrentDirectory=/journal/current/
+`), 0644)).To(Succeed())
+			instance = &isclib.Instance{Product: isclib.Iris, DataDirectory: dir, CPFFileName: "iris.cpf"}
+		})
+
+		It("Reads the database directories without their additional settings", func() {
+			Expect(instance.DatInfo()).To(Equal(map[string]isclib.Dat{
+				"APP1": {Path: "/data/app1/"},
+				"USER": {Path: "/data/user/"},
+			}))
+		})
+
+		It("Validates the data directory containing the CPF", func() {
+			Expect(instance.ValidateDataDirectory()).To(Succeed())
+		})
+
+		It("Reports both directories when the CPF is not in the data directory", func() {
+			instance.Directory = "/usr/irissys"
+			instance.DataDirectory = filepath.Join(instance.DataDirectory, "missing")
+			err := instance.ValidateDataDirectory()
+			Expect(err).To(MatchError(isclib.ErrDataDirectoryMismatch))
+			Expect(err).To(MatchError(ContainSubstring("directory: /usr/irissys, data directory: " + instance.DataDirectory)))
+		})
+
+		It("Reads the journal directories", func() {
+			Expect(instance.DeterminePrimaryJournalDirectory()).To(Equal("/journal/current/"))
+			Expect(instance.DetermineSecondaryJournalDirectory()).To(Equal("/journal/alternate/"))
+		})
+	})
+
+	Context("Directives", func() {
+		const mergeCPF = `[Actions]
+CreateDatabase:Name=APP,Directory=/data/app
+CreateDatabase:Name=APPTEMP,Directory=/data/apptemp
+CreateNamespace:Name=APP,Globals=APP
+Reload:
+
+[Startup]
+DefaultPort=1972
+`
+		It("Preserves repeated directives in order", func() {
+			cpf, err := isclib.ParseCPF(bytes.NewBufferString(mergeCPF))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cpf.Directives("Actions")).To(Equal([]isclib.CPFDirective{
+				{Action: "CreateDatabase", Arguments: []string{"Name=APP", "Directory=/data/app"}},
+				{Action: "CreateDatabase", Arguments: []string{"Name=APPTEMP", "Directory=/data/apptemp"}},
+				{Action: "CreateNamespace", Arguments: []string{"Name=APP", "Globals=APP"}},
+				{Action: "Reload", Arguments: []string{}},
+			}))
+			Expect(cpf.Section("Actions")).To(BeEmpty())
+			Expect(cpf.Directives("Startup")).To(BeEmpty())
+			Expect(cpf.Directives("Missing")).To(BeEmpty())
+		})
+
+		It("Provides access to the arguments", func() {
+			cpf, err := isclib.ParseCPF(bytes.NewBufferString(mergeCPF))
+			Expect(err).NotTo(HaveOccurred())
+			d := cpf.Directives("Actions")[0]
+			v, ok := d.Argument("Directory")
+			Expect(ok).To(BeTrue())
+			Expect(v).To(Equal("/data/app"))
+			_, ok = d.Argument("Missing")
+			Expect(ok).To(BeFalse())
+			Expect(d.String()).To(Equal("CreateDatabase:Name=APP,Directory=/data/app"))
+		})
+	})
+
+	Context("CPFDiff", func() {
+		It("Reports no differences for identical CPFs", func() {
+			a, _ := isclib.ParseCPF(bytes.NewBufferString(testCPF))
+			b, _ := isclib.ParseCPF(bytes.NewBufferString(testCPF))
+			Expect(isclib.CPFDiff(a, b)).To(BeEmpty())
+		})
+
+		It("Reports changed, removed and added keys", func() {
+			a, _ := isclib.ParseCPF(bytes.NewBufferString(testCPF))
+			b, _ := isclib.ParseCPF(bytes.NewBufferString(`[ConfigFile]
+Product=IRIS
+Version=2023.1
+
+[Databases]
+IRISSYS=/usr/irissys/mgr/
+
+[Startup]
+DefaultPort=1972
+ShutdownTimeout=300
+
+[Journal]
+FileSizeLimit=1024
+`))
+			Expect(isclib.CPFDiff(a, b)).To(Equal([]isclib.CPFFieldDiff{
+				{Section: "ConfigFile", Key: "Version", A: "2022.1", B: "2023.1", InA: true, InB: true},
+				{Section: "Databases", Key: "USER", A: "/usr/irissys/mgr/user/", InA: true},
+				{Section: "Journal", Key: "FileSizeLimit", B: "1024", InB: true},
+			}))
+		})
+	})
+
+	Context("Instance.CPFDiff", func() {
+		It("Compares the CPFs of both instances", func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(dir, "a.cpf"), []byte(testCPF), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "b.cpf"), []byte(testCPF+"\n[Journal]\nFileSizeLimit=1024\n"), 0644)).To(Succeed())
+			a := &isclib.Instance{DataDirectory: dir, CPFFileName: "a.cpf"}
+			b := &isclib.Instance{DataDirectory: dir, CPFFileName: "b.cpf"}
+			Expect(a.CPFDiff(b)).To(Equal([]isclib.CPFFieldDiff{
+				{Section: "Journal", Key: "FileSizeLimit", B: "1024", InB: true},
+			}))
+		})
+
+		It("Returns an error when a CPF cannot be read", func() {
+			dir := GinkgoT().TempDir()
+			a := &isclib.Instance{DataDirectory: dir, CPFFileName: "a.cpf"}
+			_, err := a.CPFDiff(a)
+			Expect(err).To(MatchError(os.ErrNotExist))
+		})
+	})
+
+	Context("SetCPFValue", func() {
+		const path = "/durable/iris.cpf"
+		var origFS afero.Fs
+
+		BeforeEach(func() {
+			origFS = isclib.FS
+			isclib.FS = new(afero.MemMapFs)
+			Expect(isclib.FS.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(afero.WriteFile(isclib.FS, path, []byte(testCPF), 0640)).To(Succeed())
+		})
+		AfterEach(func() {
+			isclib.FS = origFS
+		})
+
+		It("Replaces an existing value", func() {
+			Expect(isclib.SetCPFValue(path, "Startup", "DefaultPort", "51773")).To(Succeed())
+			Expect(afero.ReadFile(isclib.FS, path)).To(BeEquivalentTo(strings.Replace(testCPF, "DefaultPort=1972", "DefaultPort=51773", 1)))
+		})
+
+		It("Adds a missing key to the end of the section", func() {
+			Expect(isclib.SetCPFValue(path, "Databases", "APP", "/data/app/")).To(Succeed())
+			Expect(afero.ReadFile(isclib.FS, path)).To(BeEquivalentTo(strings.Replace(testCPF, "USER=/usr/irissys/mgr/user/\n", "USER=/usr/irissys/mgr/user/\nAPP=/data/app/\n", 1)))
+		})
+
+		It("Adds a missing section to the end of the file", func() {
+			Expect(isclib.SetCPFValue(path, "Journal", "FileSizeLimit", "1024")).To(Succeed())
+			Expect(afero.ReadFile(isclib.FS, path)).To(BeEquivalentTo(testCPF + "\n[Journal]\nFileSizeLimit=1024\n"))
+		})
+
+		It("Preserves the permissions of the file", func() {
+			Expect(isclib.SetCPFValue(path, "Startup", "DefaultPort", "51773")).To(Succeed())
+			info, err := isclib.FS.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0640)))
+		})
+
+		Context("Instance.SetSuperServerPort", func() {
+			var instance *isclib.Instance
+
+			BeforeEach(func() {
+				instance = &isclib.Instance{DataDirectory: filepath.Dir(path), CPFFileName: filepath.Base(path)}
+			})
+
+			It("Sets the DefaultPort of the Startup section", func() {
+				Expect(instance.SetSuperServerPort(51773)).To(Succeed())
+				b, err := afero.ReadFile(isclib.FS, path)
+				Expect(err).NotTo(HaveOccurred())
+				cpf, err := isclib.ParseCPF(bytes.NewReader(b))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cpf.Section("Startup")).To(HaveKeyWithValue("DefaultPort", "51773"))
+			})
+
+			It("Rejects invalid ports", func() {
+				Expect(instance.SetSuperServerPort(0)).NotTo(Succeed())
+				Expect(instance.SetSuperServerPort(65536)).NotTo(Succeed())
+				Expect(afero.ReadFile(isclib.FS, path)).To(BeEquivalentTo(testCPF))
+			})
+		})
+	})
+})
@@ -0,0 +1,127 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// Database holds the configuration of an ISC database and information about its DAT file
+type Database struct {
+	// The name of the database as configured in the CPF
+	Name string `json:"name"`
+	// The directory of the database as configured in the CPF
+	Directory string `json:"directory"`
+	// The path to the database's DAT file (CACHE.DAT, IRIS.DAT)
+	DatPath string `json:"datPath"`
+	// Whether the DAT file exists.  Permission, Owner and Group are only populated for an existing DAT file.
+	Exists     bool   `json:"exists"`
+	Permission string `json:"permission"`
+	Owner      string `json:"owner"`
+	Group      string `json:"group"`
+	// Whether the database directory is within the instance's data directory (see DataDirectory)
+	UnderDataDirectory bool `json:"underDataDirectory"`
+}
+
+// Databases will parse the instance's CPF file for its databases and inspect their DAT files.
+// It returns the databases in the order they are configured in the CPF and any error encountered.
+func (i *Instance) Databases() ([]Database, error) {
+	cpf, err := i.LoadCPF()
+	if err != nil {
+		return nil, err
+	}
+
+	dbs := make([]Database, 0)
+	if s := cpf.section("Databases"); s != nil {
+		for _, e := range s.Entries {
+			db, err := i.database(e.Key, cpfDatabaseDirectory(e.Value))
+			if err != nil {
+				return nil, err
+			}
+			dbs = append(dbs, db)
+		}
+	}
+
+	return dbs, nil
+}
+
+// DatPath will determine the expected path of the named database's DAT file (CACHE.DAT, IRIS.DAT) from the
+// instance's CPF without inspecting the file or the other databases (see Databases).
+// The database name is case-insensitive.
+// It returns the path and any error encountered.
+func (i *Instance) DatPath(databaseName string) (string, error) {
+	cpf, err := i.LoadCPF()
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := cpf.Value("Databases", strings.ToUpper(databaseName))
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrDatabaseNotFound, databaseName)
+	}
+
+	return filepath.Join(cpfDatabaseDirectory(value), i.DetermineISCDatFileName()), nil
+}
+
+// database will determine the details of the named database in the provided directory
+func (i *Instance) database(name, directory string) (Database, error) {
+	db := Database{
+		Name:               name,
+		Directory:          directory,
+		DatPath:            filepath.Join(directory, i.DetermineISCDatFileName()),
+		Exists:             true,
+		UnderDataDirectory: i.underDataDirectory(directory),
+	}
+
+	datFileInfo, err := os.Stat(db.DatPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			db.Exists = false
+			return db, nil
+		}
+		return Database{}, err
+	}
+
+	if uid, gid, ok := fileOwnership(datFileInfo); ok {
+		fileOwner, err := user.LookupId(fmt.Sprint(uid))
+		if err != nil {
+			return Database{}, err
+		}
+		db.Owner = fileOwner.Username
+		fileGroup, err := user.LookupGroupId(fmt.Sprint(gid))
+		if err != nil {
+			return Database{}, err
+		}
+		db.Group = fileGroup.Name
+	}
+	db.Permission = datFileInfo.Mode().String()
+
+	return db, nil
+}
+
+func (i *Instance) underDataDirectory(directory string) bool {
+	if i.DataDirectory == "" {
+		return false
+	}
+
+	rel, err := filepath.Rel(filepath.Clean(i.DataDirectory), filepath.Clean(directory))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
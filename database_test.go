@@ -0,0 +1,96 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("Databases", func() {
+	var (
+		instance *isclib.Instance
+		dir      string
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(dir, "mgr", "user"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "mgr", "user", isclib.IrisDatName), nil, 0660)).To(Succeed())
+		Expect(os.Chmod(filepath.Join(dir, "mgr", "user", isclib.IrisDatName), 0660)).To(Succeed())
+		cpf := "[Databases]\n" +
+			"USER=" + filepath.Join(dir, "mgr", "user") + "/\n" +
+			"APP1=/data/app1/,1,,,\n" +
+			"IRISSYS=" + filepath.Join(dir, "mgr") + "/\n"
+		Expect(os.WriteFile(filepath.Join(dir, "iris.cpf"), []byte(cpf), 0644)).To(Succeed())
+		instance = &isclib.Instance{Product: isclib.Iris, DataDirectory: dir, CPFFileName: "iris.cpf"}
+	})
+
+	It("Returns the databases in the order they are configured", func() {
+		dbs, err := instance.Databases()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dbs).To(HaveLen(3))
+		Expect([]string{dbs[0].Name, dbs[1].Name, dbs[2].Name}).To(Equal([]string{"USER", "APP1", "IRISSYS"}))
+	})
+
+	It("Inspects the DAT files", func() {
+		cur, err := user.Current()
+		Expect(err).NotTo(HaveOccurred())
+		dbs, err := instance.Databases()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dbs[0].Directory).To(Equal(filepath.Join(dir, "mgr", "user") + "/"))
+		Expect(dbs[0].DatPath).To(Equal(filepath.Join(dir, "mgr", "user", isclib.IrisDatName)))
+		Expect(dbs[0].Exists).To(BeTrue())
+		Expect(dbs[0].Owner).To(Equal(cur.Username))
+		Expect(dbs[0].Permission).To(Equal("-rw-rw----"))
+		Expect(dbs[0].UnderDataDirectory).To(BeTrue())
+		Expect(dbs[1]).To(Equal(isclib.Database{Name: "APP1", Directory: "/data/app1/", DatPath: "/data/app1/IRIS.DAT"}))
+		Expect(dbs[2].UnderDataDirectory).To(BeTrue())
+		Expect(dbs[2].Exists).To(BeFalse())
+	})
+
+	It("Keeps DatInfo consistent with the databases", func() {
+		dats, err := instance.DatInfo()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dats).To(HaveLen(3))
+		Expect(dats["APP1"]).To(Equal(isclib.Dat{Path: "/data/app1/"}))
+		Expect(dats["USER"].Exists).To(BeTrue())
+	})
+
+	Describe("DatPath", func() {
+		It("Returns the path of the named database's DAT file", func() {
+			Expect(instance.DatPath("app1")).To(Equal("/data/app1/IRIS.DAT"))
+			Expect(instance.DatPath("USER")).To(Equal(filepath.Join(dir, "mgr", "user", isclib.IrisDatName)))
+		})
+
+		It("Uses the product's DAT file name", func() {
+			instance.Product = isclib.Cache
+			Expect(instance.DatPath("APP1")).To(Equal("/data/app1/CACHE.DAT"))
+		})
+
+		It("Returns an error for an unknown database", func() {
+			_, err := instance.DatPath("MISSING")
+			Expect(err).To(MatchError(isclib.ErrDatabaseNotFound))
+		})
+	})
+})
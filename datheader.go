@@ -0,0 +1,72 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+const (
+	// The label at the start of a DAT file fits within this many bytes for both the legacy 2KB and the 8KB block formats
+	datLabelSize = 16384
+	// The label records the version string ($ZVERSION) of the instance which created or last upgraded the database, e.g.
+	// "IRIS for UNIX (Ubuntu Server LTS for x86-64) 2022.1.2 (Build 574U)"
+	datVersionPattern = `(Cache|IRIS) for [ -~]{0,100}?\b(\d{4}\.\d+(?:\.\d+)*) \(Build`
+)
+
+var (
+	datVersionRegexp = regexp.MustCompile(datVersionPattern)
+
+	// ErrDatVersionNotFound is an error signifying that the header of a DAT file does not record a version
+	ErrDatVersionNotFound = errors.New("version not found in DAT header")
+)
+
+// DatVersion will read the version of the instance which created or last upgraded the database from the header of the
+// DAT file (CACHE.DAT, IRIS.DAT) at the path provided without mounting it, e.g. "2022.1.2".
+// The layout of the header is not documented by ISC, the version is taken from the version string the instance records
+// in the label at the start of the file.
+// It returns the version and any error encountered, including an error wrapping ErrDatVersionNotFound when the header
+// does not record a version (e.g. the file is not a DAT file).
+func DatVersion(datPath string) (string, error) {
+	_, version, err := readDatHeader(datPath)
+	return version, err
+}
+
+// readDatHeader reads the product and version recorded in the header of the DAT file at the path provided
+func readDatHeader(datPath string) (Product, string, error) {
+	f, err := FS.Open(datPath)
+	if err != nil {
+		return Cache, "", err
+	}
+	defer f.Close()
+
+	label := make([]byte, datLabelSize)
+	n, err := io.ReadFull(f, label)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return Cache, "", err
+	}
+
+	m := datVersionRegexp.FindSubmatch(label[:n])
+	if m == nil {
+		return Cache, "", fmt.Errorf("%w, path: %s", ErrDatVersionNotFound, datPath)
+	}
+
+	return ParseProduct(string(m[1])), string(m[2]), nil
+}
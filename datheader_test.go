@@ -0,0 +1,84 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("DatVersion", func() {
+	const (
+		cacheVersion = "Cache for UNIX (Red Hat Enterprise Linux for x86-64) 2018.1.4 (Build 505_1U) Thu May 28 2020 10:01:40 EDT"
+		irisVersion  = "IRIS for UNIX (Ubuntu Server LTS for x86-64) 2022.1.2 (Build 574U) Fri Jan 13 2023 15:08:27 EST"
+	)
+	var origFS afero.Fs
+
+	// writeDat writes a DAT file fixture of size bytes with the version string recorded in its label at offset
+	writeDat := func(path, version string, offset, size int) {
+		dat := make([]byte, size)
+		for n := range dat {
+			dat[n] = byte(n % 251)
+		}
+		copy(dat[offset:], version+"\x00")
+		Expect(afero.WriteFile(FS, path, dat, 0660)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		origFS = FS
+		FS = new(afero.MemMapFs)
+	})
+	AfterEach(func() {
+		FS = origFS
+	})
+
+	It("Reads the version from a Caché DAT header", func() {
+		writeDat("/db/CACHE.DAT", cacheVersion, 1536, 65536)
+		Expect(DatVersion("/db/CACHE.DAT")).To(Equal("2018.1.4"))
+	})
+
+	It("Reads the version from an IRIS DAT header", func() {
+		writeDat("/db/IRIS.DAT", irisVersion, 4096, 65536)
+		Expect(DatVersion("/db/IRIS.DAT")).To(Equal("2022.1.2"))
+	})
+
+	It("Reads the version from a DAT smaller than the label", func() {
+		writeDat("/db/CACHE.DAT", cacheVersion, 256, 2048)
+		Expect(DatVersion("/db/CACHE.DAT")).To(Equal("2018.1.4"))
+	})
+
+	It("Does not read versions beyond the label", func() {
+		writeDat("/db/IRIS.DAT", irisVersion, 32768, 65536)
+		_, err := DatVersion("/db/IRIS.DAT")
+		Expect(err).To(MatchError(ErrDatVersionNotFound))
+	})
+
+	It("Returns an error for a file which is not a DAT", func() {
+		Expect(afero.WriteFile(FS, "/db/notes.txt", []byte("2022.1.2"), 0644)).To(Succeed())
+		_, err := DatVersion("/db/notes.txt")
+		Expect(err).To(MatchError(ErrDatVersionNotFound))
+	})
+
+	It("Returns an error when the DAT does not exist", func() {
+		_, err := DatVersion("/db/IRIS.DAT")
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+})
@@ -0,0 +1,58 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// ExecuteOptions are the options used to alter how code is executed (see ExecuteWithOptions).
+// The zero value executes code exactly as Execute does.
+type ExecuteOptions struct {
+	// KeepRoutine skips the removal of the temporary routine from the namespace after the execution so it can be
+	// inspected or stepped through.  The caller is responsible for deleting the routine.
+	KeepRoutine bool
+
+	// KeepTempFile skips the removal of the temporary file containing the generated XML (the wrapper around the code)
+	// which is imported into the namespace so it can be inspected.  The caller is responsible for deleting the file.
+	KeepTempFile bool
+}
+
+// ExecuteResult is the result of executing code with ExecuteWithOptions.
+type ExecuteResult struct {
+	// Output is the output of the execution
+	Output string
+	// RoutineName is the name of the temporary routine the code was imported as
+	RoutineName string
+	// TempFilePath is the path to the temporary file imported into the namespace, it is only populated when the file
+	// was kept (see KeepTempFile)
+	TempFilePath string
+}
+
+// ExecuteWithOptions will read code from the provided io.Reader and execute it in the provided namespace (see Execute)
+// as altered by the provided options.
+// Executions are never retried (see SetExecuteRetry).
+// It returns the result of the execution and any error encountered.  The routine name of the result is populated
+// whenever the code was imported and the temporary file path whenever the file was kept, even if the execution itself
+// failed.
+func (i *Instance) ExecuteWithOptions(namespace string, codeReader io.Reader, opts ExecuteOptions) (ExecuteResult, error) {
+	var out bytes.Buffer
+	e, err := i.executeWithOutput(context.Background(), namespace, codeReader, executionIO{stdout: &out}, opts)
+	return ExecuteResult{Output: out.String(), RoutineName: e.routineName, TempFilePath: e.tempFilePath}, err
+}
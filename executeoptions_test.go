@@ -0,0 +1,199 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("ExecuteWithOptions", func() {
+	// The fake session records every invocation and prints the execution output when the routine is run
+	var (
+		instance    *isclib.Instance
+		invocations string
+	)
+
+	BeforeEach(func() {
+		isclib.SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+		invocations = filepath.Join(GinkgoT().TempDir(), "invocations")
+		instance = &isclib.Instance{Name: "INSTTEST", SessionPath: isclib.WriteFakeSession(isclib.FakeSession{
+			Prelude: `echo "$@" >> ` + invocations,
+			Main:    `echo "executed"`,
+		})}
+	})
+	AfterEach(func() {
+		isclib.SetExecuteTemporaryDirectory("")
+	})
+
+	deletions := func() int {
+		b, err := os.ReadFile(invocations)
+		Expect(err).NotTo(HaveOccurred())
+		return strings.Count(string(b), "%Routine).Delete")
+	}
+
+	It("Removes the temporary routine by default", func() {
+		result, err := instance.ExecuteWithOptions("USER", strings.NewReader("MAIN\n quit\n\n"), isclib.ExecuteOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Output).To(Equal("executed\n"))
+		Expect(result.RoutineName).NotTo(BeEmpty())
+		Expect(deletions()).To(Equal(1))
+	})
+
+	It("Keeps the temporary routine when requested", func() {
+		result, err := instance.ExecuteWithOptions("USER", strings.NewReader("MAIN\n quit\n\n"), isclib.ExecuteOptions{KeepRoutine: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Output).To(Equal("executed\n"))
+		Expect(result.RoutineName).To(HavePrefix(isclib.ExecuteTempPrefix()))
+		Expect(deletions()).To(BeZero())
+	})
+
+	It("Removes exactly the routine of each concurrent execution", func() {
+		const executions = 8
+		names := make([]string, executions)
+		var wg sync.WaitGroup
+		for n := range executions {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				result, err := instance.ExecuteWithOptions("USER", strings.NewReader("MAIN\n quit\n\n"), isclib.ExecuteOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				names[n] = result.RoutineName
+			}()
+		}
+		wg.Wait()
+
+		b, err := os.ReadFile(invocations)
+		Expect(err).NotTo(HaveOccurred())
+		seen := make(map[string]bool)
+		for _, name := range names {
+			Expect(name).To(MatchRegexp(`^` + isclib.ExecuteTempPrefix() + `[0-9A-F]{16}$`))
+			Expect(seen).NotTo(HaveKey(name), "unique routine name")
+			seen[name] = true
+			Expect(strings.Count(string(b), `%Routine).Delete("`+name+`",`)).To(Equal(1), name)
+		}
+		Expect(deletions()).To(Equal(executions))
+	})
+
+	It("Removes the temporary file by default", func() {
+		result, err := instance.ExecuteWithOptions("USER", strings.NewReader("MAIN\n quit\n\n"), isclib.ExecuteOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.TempFilePath).To(BeEmpty())
+		entries, err := os.ReadDir(isclib.ExecuteTemporaryDirectory())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("Keeps the temporary file when requested", func() {
+		result, err := instance.ExecuteWithOptions("USER", strings.NewReader("MAIN\n write \"kept\"\n quit\n\n"), isclib.ExecuteOptions{KeepTempFile: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.Dir(result.TempFilePath)).To(Equal(isclib.ExecuteTemporaryDirectory()))
+		Expect(filepath.Base(result.TempFilePath)).To(Equal(result.RoutineName))
+		b, err := os.ReadFile(result.TempFilePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(ContainSubstring(`<Routine name="` + result.RoutineName + `"`))
+		Expect(string(b)).To(ContainSubstring(" write \"kept\""))
+		Expect(deletions()).To(Equal(1))
+	})
+})
+
+var _ = Describe("ExecuteWithContext", func() {
+	// The fake session records every invocation and hangs when the routine is run
+	var (
+		instance    *isclib.Instance
+		invocations string
+	)
+
+	BeforeEach(func() {
+		isclib.SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+		invocations = filepath.Join(GinkgoT().TempDir(), "invocations")
+		instance = &isclib.Instance{Name: "INSTTEST", SessionPath: isclib.WriteFakeSession(isclib.FakeSession{
+			Prelude: `echo "$@" >> ` + invocations,
+			Main:    "exec sleep 10",
+		})}
+	})
+	AfterEach(func() {
+		isclib.SetExecuteTemporaryDirectory("")
+	})
+
+	It("Kills the session when the context is done and removes the temporary routine", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		err := instance.ExecuteWithContext(ctx, "USER", strings.NewReader("MAIN\n for  {}\n\n"), io.Discard)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		b, err := os.ReadFile(invocations)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(ContainSubstring("%Routine).Delete"))
+	})
+
+	It("Kills the import when the context is done", func() {
+		instance.SessionPath = isclib.WriteFakeSession(isclib.FakeSession{Load: "exec sleep 10"})
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		err := instance.ExecuteWithContext(ctx, "USER", strings.NewReader("MAIN\n quit\n\n"), io.Discard)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("Does not run the code when the context is already done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(instance.ExecuteWithContext(ctx, "USER", strings.NewReader("MAIN\n quit\n\n"), io.Discard)).To(MatchError(context.Canceled))
+		Expect(invocations).NotTo(BeAnExistingFile())
+	})
+})
+
+var _ = Describe("ExecuteWithIO", func() {
+	// The fake session answers every line of its input and reports on stderr when the routine is run
+	var instance *isclib.Instance
+
+	BeforeEach(func() {
+		isclib.SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+		instance = &isclib.Instance{Name: "INSTTEST", SessionPath: isclib.WriteFakeSession(isclib.FakeSession{Main: `
+    while read -r line; do echo "read $line"; done
+    echo "done" >&2`})}
+	})
+	AfterEach(func() {
+		isclib.SetExecuteTemporaryDirectory("")
+	})
+
+	It("Connects the session to the provided streams", func() {
+		var stdout, stderr strings.Builder
+		err := instance.ExecuteWithIO("USER", strings.NewReader("MAIN\n read x\n quit\n\n"), strings.NewReader("a\nb\n"), &stdout, &stderr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.String()).To(Equal("read a\nread b\n"))
+		Expect(stderr.String()).To(Equal("done\n"))
+	})
+
+	It("Provides the input after the session credentials", func() {
+		instance.SetSessionCredentials("_SYSTEM", "SYS")
+		var stdout strings.Builder
+		err := instance.ExecuteWithIO("USER", strings.NewReader("MAIN\n read x\n quit\n\n"), strings.NewReader("a\n"), &stdout, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.String()).To(Equal("read _SYSTEM\nread SYS\nread a\n"))
+	})
+})
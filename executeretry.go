@@ -0,0 +1,108 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrTransientSessionError is an error signifying that an execution was still failing with a transient session
+	// error after all of its retries were exhausted
+	ErrTransientSessionError = errors.New("transient session error persisted after retries")
+
+	// The session output of errors which are expected to clear up on their own (e.g. while the instance is starting)
+	transientSessionErrors = []string{
+		"<DIRECTORY>",
+		signOnInhibitedMessage,
+		"Startup of InterSystems IRIS is in progress",
+		"Startup of Cache is in progress",
+	}
+)
+
+// SetExecuteRetry will configure the instance to retry executions (see Execute) which fail with a known transient
+// session error, such as a namespace's database which is still being mounted shortly after the instance started.
+// Executions are attempted at most attempts times, waiting backoff before the first retry and doubling the wait for each
+// subsequent retry up to maxBackoff.  A maxBackoff of 0 does not limit the wait.
+// Errors in the executed code itself are never retried.
+func (i *Instance) SetExecuteRetry(attempts int, backoff, maxBackoff time.Duration) {
+	i.SetExecuteRetryPolicy(RetryPolicy{Attempts: attempts, Backoff: backoff, MaxBackoff: maxBackoff})
+}
+
+// SetExecuteRetryPolicy will configure the instance to retry executions failing with a known transient session error
+// according to the provided policy (see SetExecuteRetry).  Unlike SetExecuteRetry every field of the policy, including
+// Jitter, can be set.
+func (i *Instance) SetExecuteRetryPolicy(policy RetryPolicy) {
+	log.WithFields(log.Fields{"attempts": policy.Attempts, "backoff": policy.Backoff, "maxBackoff": policy.MaxBackoff, "jitter": policy.Jitter}).Debug("Configured execute retry")
+	i.executeRetry = &policy
+}
+
+// ClearExecuteRetry will configure the instance to attempt all future executions only once.
+func (i *Instance) ClearExecuteRetry() {
+	log.Debug("Removing execute retry")
+	i.executeRetry = nil
+}
+
+// IsTransientSessionError reports whether the provided session output contains a known transient session error.
+func IsTransientSessionError(output string) bool {
+	for _, e := range transientSessionErrors {
+		if strings.Contains(output, e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i *Instance) executeWithRetry(namespace string, codeReader io.Reader) (string, error) {
+	code, err := io.ReadAll(codeReader)
+	if err != nil {
+		return "", err
+	}
+
+	var out string
+	attempt := 0
+	err = i.executeRetry.Retry(func() error {
+		attempt++
+		var buf bytes.Buffer
+		e, err := i.executeWithOutput(context.Background(), namespace, bytes.NewReader(code), executionIO{stdout: &buf}, ExecuteOptions{})
+		out = buf.String()
+		if err == nil || !IsTransientSessionError(e.importOutput+out) {
+			return StopRetrying(err)
+		}
+
+		log.WithFields(log.Fields{
+			"instance":  i.Name,
+			"namespace": namespace,
+			"attempt":   attempt,
+		}).WithError(err).Debug("Transient session error, retrying execution")
+		return err
+	})
+	if errors.Is(err, ErrRetriesExhausted) {
+		return out, fmt.Errorf("%w: %w", ErrTransientSessionError, err)
+	}
+
+	return out, err
+}
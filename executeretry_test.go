@@ -0,0 +1,117 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExecuteRetry", func() {
+	var (
+		instance *Instance
+		dir      string
+		sleeps   []time.Duration
+	)
+
+	// The fake session fails with the provided output for the first failures invocations and then succeeds
+	writeSession := func(failures int, output string) {
+		instance.SessionPath = WriteFakeSession(FakeSession{Prelude: fmt.Sprintf(`count=$(cat %[1]s 2>/dev/null || echo 0)
+echo $((count + 1)) > %[1]s
+if [ "$count" -lt %[2]d ]; then
+  echo "%[3]s"
+  exit 1
+fi`, filepath.Join(dir, "count"), failures, output)})
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+		sleeps = nil
+		retrySleep = func(d time.Duration) {
+			sleeps = append(sleeps, d)
+		}
+		instance = &Instance{Name: "INSTTEST"}
+	})
+	AfterEach(func() {
+		SetExecuteTemporaryDirectory("")
+		retrySleep = time.Sleep
+	})
+
+	Context("No retry is configured", func() {
+		It("Does not retry transient errors", func() {
+			writeSession(1, "<DIRECTORY>")
+			_, err := instance.ExecuteString("USER", "MAIN\n quit\n\n")
+			Expect(err).To(HaveOccurred())
+			Expect(sleeps).To(BeEmpty())
+		})
+	})
+
+	Context("Retry is configured", func() {
+		BeforeEach(func() {
+			instance.SetExecuteRetry(4, time.Second, 3*time.Second)
+		})
+		It("Retries transient errors with a capped backoff", func() {
+			writeSession(3, "<DIRECTORY>")
+			_, err := instance.ExecuteString("USER", "MAIN\n quit\n\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sleeps).To(Equal([]time.Duration{time.Second, 2 * time.Second, 3 * time.Second}))
+		})
+		It("Gives up once the attempts are exhausted", func() {
+			writeSession(10, "<DIRECTORY>")
+			_, err := instance.ExecuteString("USER", "MAIN\n quit\n\n")
+			Expect(err).To(MatchError(ErrTransientSessionError))
+			Expect(sleeps).To(HaveLen(3))
+		})
+		It("Does not retry genuine errors", func() {
+			writeSession(1, "<UNDEFINED>")
+			_, err := instance.ExecuteString("USER", "MAIN\n quit\n\n")
+			Expect(err).To(HaveOccurred())
+			Expect(err).NotTo(MatchError(ErrTransientSessionError))
+			Expect(sleeps).To(BeEmpty())
+		})
+		It("Randomizes the backoff when the policy has jitter", func() {
+			instance.SetExecuteRetryPolicy(RetryPolicy{Attempts: 2, Backoff: time.Second, Jitter: 0.5})
+			writeSession(1, "<DIRECTORY>")
+			_, err := instance.ExecuteString("USER", "MAIN\n quit\n\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sleeps).To(HaveLen(1))
+			Expect(sleeps[0]).To(BeNumerically("~", time.Second, 500*time.Millisecond))
+		})
+		It("Does not retry once cleared", func() {
+			instance.ClearExecuteRetry()
+			writeSession(1, "<DIRECTORY>")
+			_, err := instance.ExecuteString("USER", "MAIN\n quit\n\n")
+			Expect(err).To(HaveOccurred())
+			Expect(sleeps).To(BeEmpty())
+		})
+	})
+
+	DescribeTable("IsTransientSessionError",
+		func(output string, expected bool) {
+			Expect(IsTransientSessionError(output)).To(Equal(expected))
+		},
+		Entry("Unmounted database", "ERROR #5002: <DIRECTORY>", true),
+		Entry("Inhibited sign-ons", "Sign-on inhibited.", true),
+		Entry("Code error", "<UNDEFINED>MAIN+1^ELEXEC123 *x", false),
+		Entry("No output", "", false),
+	)
+})
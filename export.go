@@ -0,0 +1,84 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	exportFmtStr = `##class(%%SYSTEM.OBJ).Export("%s","%s","%s")`
+	// ExportFileName is the name of the file ExportSource writes the exported items to within the output directory
+	ExportFileName = "export.xml"
+)
+
+var (
+	// ErrNoExportItems is an error signifying that no items were provided to export
+	ErrNoExportItems = errors.New("no items to export")
+	// ErrExportFailed is an error signifying that the export did not finish successfully
+	ErrExportFailed = errors.New("export failed")
+)
+
+// ExportSource will export the provided items (e.g. App.Person.cls, APPRTN.mac, ^AppData.gbl) from the namespace to
+// an XML file named ExportFileName in outputDir using the provided qualifiers.  It is the inverse of ImportSource and
+// the resulting file can be imported with it.
+// outputDir is a directory on the instance's host and must already exist.  Items may include wildcards supported by
+// $SYSTEM.OBJ.Export (e.g. App.*.cls).
+// An empty namespace exports from the instance's default namespace (see DefaultNamespace).
+// It returns any output of the export and any error encountered.
+func (i *Instance) ExportSource(namespace, outputDir string, items []string, qualifiers ...string) (string, error) {
+	if len(items) == 0 {
+		return "", ErrNoExportItems
+	}
+
+	namespace, err := i.resolveNamespace(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(outputDir, ExportFileName)
+	qstr := strings.TrimSpace(strings.Join(qualifiers, ""))
+	cmd := fmt.Sprintf(exportFmtStr, strings.Join(items, ","), path, qstr)
+	l := log.WithFields(log.Fields{
+		"instance":   i.Name,
+		"namespace":  namespace,
+		"items":      items,
+		"path":       path,
+		"qualifiers": qstr,
+		"command":    cmd,
+	})
+	l.Debug("Attempting to export source")
+	ctx, cancel := commandContext()
+	defer cancel()
+	o, err := i.SessionCommandContext(ctx, namespace, cmd).CombinedOutput()
+	out := string(o)
+	l.WithField("output", out).Debug("export command result")
+	if err != nil {
+		return out, commandContextError(ctx, err)
+	}
+
+	if !strings.Contains(out, "Export finished successfully.") {
+		return out, ErrExportFailed
+	}
+
+	return out, nil
+}
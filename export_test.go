@@ -0,0 +1,67 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("ExportSource", func() {
+	var (
+		instance *isclib.Instance
+		argsFile string
+	)
+
+	// The fake session records its arguments and reports the provided result
+	writeSession := func(result string) {
+		dir := GinkgoT().TempDir()
+		argsFile = filepath.Join(dir, "args")
+		script := filepath.Join(dir, "session")
+		Expect(os.WriteFile(script, []byte("#!/bin/sh\nprintf '%s\\n' \"$@\" > "+argsFile+"\necho '"+result+"'\n"), 0755)).To(Succeed())
+		instance.SessionPath = script
+	}
+
+	BeforeEach(func() {
+		instance = &isclib.Instance{Name: "INSTTEST"}
+	})
+
+	It("Runs the export in the namespace", func() {
+		writeSession("Export finished successfully.")
+		out, err := instance.ExportSource("USER", "/tmp/out", []string{"App.Person.cls", "APPRTN.mac"}, "/display=none")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("Export finished successfully.\n"))
+		Expect(os.ReadFile(argsFile)).To(Equal([]byte(
+			"INSTTEST\n-U\nUSER\n" + `##class(%SYSTEM.OBJ).Export("App.Person.cls,APPRTN.mac","/tmp/out/export.xml","/display=none")` + "\n",
+		)))
+	})
+
+	It("Returns ErrExportFailed when the export does not finish successfully", func() {
+		writeSession("ERROR #5001: No items selected")
+		_, err := instance.ExportSource("USER", "/tmp/out", []string{"Nope.cls"})
+		Expect(err).To(MatchError(isclib.ErrExportFailed))
+	})
+
+	It("Requires items to export", func() {
+		_, err := instance.ExportSource("USER", "/tmp/out", nil)
+		Expect(err).To(MatchError(isclib.ErrNoExportItems))
+	})
+})
@@ -0,0 +1,156 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	followPollInterval = 250 * time.Millisecond
+)
+
+// FollowMessagesLog will follow the console log of the instance (messages.log or cconsole.log, see ConsoleLogPath)
+// calling onLine with each line written to the log after it was called, much like tail -f.
+// Lines are passed to onLine without their line endings and only once they are complete.
+// If the log is truncated or rotated (e.g. when the instance restarts) it is reopened and followed from its start,
+// after the remainder of a rotated log has been read.
+// It blocks until the context is canceled at which point it returns nil.
+// It returns any error encountered locating or reading the log.
+func (i *Instance) FollowMessagesLog(ctx context.Context, onLine func(string)) error {
+	p, err := i.ConsoleLogPath()
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{"instance": i.Name, "path": p}).Debug("Following console log")
+	return followLog(ctx, p, onLine)
+}
+
+func followLog(ctx context.Context, path string, onLine func(string)) error {
+	f, err := FS.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close()
+	}()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(followPollInterval)
+	defer ticker.Stop()
+
+	r := bufio.NewReader(f)
+	var partial string
+	// readLines passes each complete line up to the end of the file being read to onLine
+	readLines := func() error {
+		for {
+			line, err := r.ReadString('\n')
+			offset += int64(len(line))
+			if errors.Is(err, io.EOF) {
+				partial += line
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			onLine(strings.TrimRight(partial+line, "\r\n"))
+			partial = ""
+		}
+	}
+
+	for {
+		if err := readLines(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := FS.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			// the log has been moved aside and its replacement has not been created yet
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		switch {
+		case !sameFile(info, current):
+			log.WithField("path", path).Debug("Console log rotated, reopening")
+			nf, err := FS.Open(path)
+			if err != nil {
+				return err
+			}
+
+			// the lines written to the old log before it was rotated are read first, its last line is complete as
+			// nothing more will be written to it
+			if err := readLines(); err != nil {
+				nf.Close()
+				return err
+			}
+			if partial != "" {
+				onLine(strings.TrimRight(partial, "\r\n"))
+			}
+			f.Close()
+			f, info = nf, current
+		case current.Size() < offset:
+			log.WithField("path", path).Debug("Console log truncated, reading from the start")
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+
+		r.Reset(f)
+		offset = 0
+		partial = ""
+	}
+}
+
+// sameFile reports whether both file infos describe the same file.
+// File systems which cannot identify files (e.g. in memory file systems) are assumed to be the same file.
+func sameFile(a, b os.FileInfo) bool {
+	adev, aino, aok := fileIdentity(a)
+	bdev, bino, bok := fileIdentity(b)
+	if !aok || !bok {
+		return true
+	}
+
+	return adev == bdev && aino == bino
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("FollowMessagesLog", func() {
+	var (
+		instance *Instance
+		logPath  string
+		mu       sync.Mutex
+		lines    []string
+		origFS   afero.Fs
+	)
+
+	onLine := func(line string) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, line)
+	}
+	followed := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), lines...)
+	}
+	appendLog := func(s string) {
+		f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		_, err = f.WriteString(s)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	// follow runs fn in the background returning a function which cancels it and waits for it to return
+	follow := func(fn func(context.Context) error) func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- fn(ctx)
+		}()
+
+		return func() {
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+		}
+	}
+
+	BeforeEach(func() {
+		origFS = FS
+		FS = afero.NewOsFs()
+		followPollInterval = 10 * time.Millisecond
+		lines = nil
+
+		dir := GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(dir, "mgr"), 0755)).To(Succeed())
+		instance = &Instance{Name: "INSTTEST", Product: Iris, Directory: dir, DataDirectory: dir}
+		logPath = filepath.Join(dir, "mgr", "messages.log")
+		Expect(os.WriteFile(logPath, []byte("existing line\n"), 0644)).To(Succeed())
+	})
+	AfterEach(func() {
+		FS = origFS
+		followPollInterval = 250 * time.Millisecond
+	})
+
+	It("Calls onLine for each new complete line until canceled", func() {
+		stop := follow(func(ctx context.Context) error { return instance.FollowMessagesLog(ctx, onLine) })
+
+		// give the follower a chance to open the log before writing to it
+		time.Sleep(50 * time.Millisecond)
+		appendLog("first line\nsecond ")
+		Eventually(followed).Should(Equal([]string{"first line"}))
+		Consistently(followed, 50*time.Millisecond).Should(Equal([]string{"first line"}))
+		appendLog("line\r\n")
+		Eventually(followed).Should(Equal([]string{"first line", "second line"}))
+
+		stop()
+	})
+
+	It("Follows the new log when it is rotated", func() {
+		stop := follow(func(ctx context.Context) error { return instance.FollowMessagesLog(ctx, onLine) })
+		defer stop()
+
+		time.Sleep(50 * time.Millisecond)
+		appendLog("before rotation\n")
+		Eventually(followed).Should(Equal([]string{"before rotation"}))
+
+		Expect(os.Rename(logPath, logPath+".old")).To(Succeed())
+		Expect(os.WriteFile(logPath, []byte("after rotation\n"), 0644)).To(Succeed())
+		Eventually(followed).Should(Equal([]string{"before rotation", "after rotation"}))
+	})
+
+	It("Reads the rest of the rotated log before following the new log", func() {
+		stop := follow(func(ctx context.Context) error { return instance.FollowMessagesLog(ctx, onLine) })
+		defer stop()
+
+		time.Sleep(50 * time.Millisecond)
+		// the instance may still write to the log it has open after the log was moved aside
+		Expect(os.Rename(logPath, logPath+".old")).To(Succeed())
+		old, err := os.OpenFile(logPath+".old", os.O_APPEND|os.O_WRONLY, 0644)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = old.WriteString("shutting down\nlast line")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(old.Close()).To(Succeed())
+		Expect(os.WriteFile(logPath, []byte("after rotation\n"), 0644)).To(Succeed())
+		Eventually(followed).Should(Equal([]string{"shutting down", "last line", "after rotation"}))
+	})
+
+	Context("Using an in memory file system", func() {
+		BeforeEach(func() {
+			FS = new(afero.MemMapFs)
+			Expect(afero.WriteFile(FS, logPath, []byte("existing line\n"), 0644)).To(Succeed())
+		})
+
+		It("Reads from the start of the log when it is truncated", func() {
+			stop := follow(func(ctx context.Context) error { return followLog(ctx, logPath, onLine) })
+			defer stop()
+
+			time.Sleep(50 * time.Millisecond)
+			f, err := FS.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = f.WriteString("before truncation\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Close()).To(Succeed())
+			Eventually(followed).Should(Equal([]string{"before truncation"}))
+
+			Expect(afero.WriteFile(FS, logPath, []byte("restarted\n"), 0644)).To(Succeed())
+			Eventually(followed).Should(Equal([]string{"before truncation", "restarted"}))
+		})
+	})
+
+	It("Returns an error when there is no console log", func() {
+		Expect(os.Remove(logPath)).To(Succeed())
+		Expect(instance.FollowMessagesLog(context.Background(), onLine)).To(MatchError(os.ErrNotExist))
+	})
+})
@@ -0,0 +1,118 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	cspGatewayConfigFile = "CSP.ini"
+	// The gateway configuration has a section per server and a section per application path naming its server
+	cspGatewayServerIndex  = "SYSTEM_INDEX"
+	cspGatewayRootPath     = "APP_PATH:/"
+	cspGatewayLocalServer  = "LOCAL"
+	cspGatewayServerKey    = "Default_Server"
+	cspGatewayAddressKey   = "Ip_Address"
+	cspGatewayPortKey      = "TCP_Port"
+	cspGatewayMirrorKey    = "Mirror_Aware"
+	cspGatewayEnabledValue = "Enabled"
+)
+
+var (
+	// ErrCSPGatewayServerNotFound is an error signifying that the CSP gateway configuration does not define the server
+	// used by the gateway
+	ErrCSPGatewayServerNotFound = errors.New("CSP gateway server not found")
+)
+
+// CSPGatewayConfig is the configuration of the server the instance's CSP gateway connects to by default
+type CSPGatewayConfig struct {
+	// The name of the server configuration (e.g. LOCAL)
+	Server string
+	// The address of the instance the gateway connects to
+	Host string
+	// The SuperServer port of the instance the gateway connects to
+	Port int
+	// Whether the gateway follows the primary when the instance is a mirror member
+	MirrorAware bool
+}
+
+// CSPGatewayConfigPath returns the path to the configuration file of the CSP gateway installed with the instance
+func (i *Instance) CSPGatewayConfigPath() string {
+	return filepath.Join(i.CSPDirectory(), "bin", cspGatewayConfigFile)
+}
+
+// CSPGatewayConfig will read the configuration of the CSP gateway installed with the instance (see
+// CSPGatewayConfigPath) for the server used by default.  That is the server of the root application path, the first
+// enabled server when there is none or LOCAL.
+// It returns the configuration and any error encountered, including ErrCSPGatewayServerNotFound if the server is not
+// configured.
+func (i *Instance) CSPGatewayConfig() (CSPGatewayConfig, error) {
+	file, err := os.Open(i.CSPGatewayConfigPath())
+	if err != nil {
+		return CSPGatewayConfig{}, err
+	}
+	defer file.Close()
+
+	// the gateway configuration is an ini file in the same format as a CPF
+	ini, err := ParseCPF(file)
+	if err != nil {
+		return CSPGatewayConfig{}, err
+	}
+
+	server := cspGatewayDefaultServer(ini)
+	s := ini.section(server)
+	if s == nil {
+		return CSPGatewayConfig{}, fmt.Errorf("%w, server: %s, path: %s", ErrCSPGatewayServerNotFound, server, i.CSPGatewayConfigPath())
+	}
+
+	values := ini.Section(server)
+	config := CSPGatewayConfig{
+		Server:      server,
+		Host:        values[cspGatewayAddressKey],
+		MirrorAware: values[cspGatewayMirrorKey] == "1",
+	}
+
+	if p := values[cspGatewayPortKey]; p != "" {
+		if config.Port, err = strconv.Atoi(p); err != nil {
+			return CSPGatewayConfig{}, fmt.Errorf("invalid CSP gateway port %q: %w", p, err)
+		}
+	}
+
+	return config, nil
+}
+
+func cspGatewayDefaultServer(ini *CPF) string {
+	if server, ok := ini.Value(cspGatewayRootPath, cspGatewayServerKey); ok && server != "" {
+		return server
+	}
+
+	if s := ini.section(cspGatewayServerIndex); s != nil {
+		for _, e := range s.Entries {
+			if strings.EqualFold(e.Value, cspGatewayEnabledValue) {
+				return e.Key
+			}
+		}
+	}
+
+	return cspGatewayLocalServer
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("CSPGatewayConfig", func() {
+	var instance *isclib.Instance
+
+	writeConfig := func(config string) {
+		Expect(os.MkdirAll(filepath.Dir(instance.CSPGatewayConfigPath()), 0755)).To(Succeed())
+		Expect(os.WriteFile(instance.CSPGatewayConfigPath(), []byte(config), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		instance = &isclib.Instance{Name: "INSTTEST", Directory: GinkgoT().TempDir()}
+	})
+
+	It("Reads the server of the root application path", func() {
+		writeConfig(`[SYSTEM]
+Server_Response_Timeout=60
+
+[SYSTEM_INDEX]
+LOCAL=Enabled
+PRIMARY=Enabled
+
+[LOCAL]
+Ip_Address=127.0.0.1
+TCP_Port=1972
+
+[PRIMARY]
+Ip_Address=db.example.com
+TCP_Port=51773
+Mirror_Aware=1
+
+[APP_PATH:/]
+Default_Server=PRIMARY
+`)
+		Expect(instance.CSPGatewayConfig()).To(Equal(isclib.CSPGatewayConfig{Server: "PRIMARY", Host: "db.example.com", Port: 51773, MirrorAware: true}))
+	})
+
+	It("Falls back to the first enabled server", func() {
+		writeConfig(`[SYSTEM_INDEX]
+OLD=Disabled
+LOCAL=Enabled
+
+[LOCAL]
+Ip_Address=127.0.0.1
+TCP_Port=1972
+`)
+		Expect(instance.CSPGatewayConfig()).To(Equal(isclib.CSPGatewayConfig{Server: "LOCAL", Host: "127.0.0.1", Port: 1972}))
+	})
+
+	It("Returns ErrCSPGatewayServerNotFound when the server is not configured", func() {
+		writeConfig(`[APP_PATH:/]
+Default_Server=MISSING
+`)
+		_, err := instance.CSPGatewayConfig()
+		Expect(err).To(MatchError(isclib.ErrCSPGatewayServerNotFound))
+	})
+
+	It("Returns an error when the gateway is not installed", func() {
+		_, err := instance.CSPGatewayConfig()
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+})
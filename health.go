@@ -0,0 +1,125 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// A trivial class method call which only succeeds if the instance accepts sessions
+	healthPingCommand = "##class(%SYSTEM.Version).GetNumber()"
+)
+
+var (
+	procDirectory = "/proc"
+	// The longest a session is given to respond to the health check
+	healthPingTimeout = 30 * time.Second
+)
+
+// IsHealthy will determine whether the instance is actually running rather than just reported as running.
+// An instance which crashes can still be reported as running by qlist, so the instance is updated (see Update) and, if
+// it is reported as running, checked for processes running the instance's executables and for a session responding
+// within a time limit.
+// The process check reads the executables of processes from /proc so it is only made on Linux, elsewhere (or when the
+// processes cannot all be inspected) the health of the instance is determined by the session alone.
+// It returns false if the instance is not running or is reported as running but does not respond, and any error
+// encountered.
+func (i *Instance) IsHealthy() (bool, error) {
+	if err := i.Update(); err != nil {
+		return false, err
+	}
+
+	ilog := log.WithFields(log.Fields{"instance": i.Name, "status": i.Status})
+	if !i.Status.Up() {
+		ilog.Debug("Instance is not running")
+		return false, nil
+	}
+
+	running, known, err := i.processesRunning()
+	if err != nil {
+		return false, err
+	}
+
+	if !running && known {
+		ilog.Warn("Instance is reported as running but none of its processes exist")
+		return false, nil
+	} else if !running {
+		ilog.Debug("Unable to inspect every process, relying on the session to determine health")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthPingTimeout)
+	defer cancel()
+	out, code, err := i.runSessionContext(ctx, systemNamespace, healthPingCommand)
+	if errors.Is(err, context.DeadlineExceeded) {
+		ilog.WithField("timeout", healthPingTimeout).Warn("Instance is reported as running but did not respond in time")
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if code != 0 {
+		ilog.WithFields(log.Fields{"exitCode": code, "output": out}).Warn("Instance is reported as running but does not respond")
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// processesRunning reports whether any process is running an executable from the instance's bin directory.
+// When no such process is found it also reports whether that is known, it is not when the processes cannot be
+// inspected (e.g. there is no /proc because this is not Linux, or the processes belong to other users).
+func (i *Instance) processesRunning() (running bool, known bool, err error) {
+	entries, err := os.ReadDir(procDirectory)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, false, nil
+	} else if err != nil {
+		return false, false, err
+	}
+
+	known = true
+	bin := i.BinDirectory() + string(filepath.Separator)
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.Trim(entry.Name(), "0123456789") != "" {
+			continue
+		}
+
+		exe, err := os.Readlink(filepath.Join(procDirectory, entry.Name(), "exe"))
+		if errors.Is(err, fs.ErrPermission) {
+			// processes belonging to other users cannot be inspected without privileges, one may be the instance's
+			known = false
+			continue
+		} else if err != nil {
+			// the process has exited or has no executable (e.g. a kernel thread)
+			continue
+		}
+
+		if strings.HasPrefix(exe, bin) {
+			return true, true, nil
+		}
+	}
+
+	return false, known, nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// FakeSession describes a shell script which stands in for the csession/iris binary when running sessions in tests.
+// It is exported (only to the tests) so the specs of both test packages can share it.
+type FakeSession struct {
+	// Prelude is run by every invocation of the session before it looks at its arguments
+	Prelude string
+	// Main is run by the session which runs the imported EnsLibMain routine, it defaults to doing nothing
+	Main string
+	// Load is run by the session which imports the routine, it defaults to reporting a successful load
+	Load string
+}
+
+// WriteFakeSession writes the script for the fake session to a temporary directory and returns its path.
+// The session which removes the temporary routine does nothing.
+func WriteFakeSession(session FakeSession) string {
+	main, load := session.Main, session.Load
+	if main == "" {
+		main = ":"
+	}
+
+	if load == "" {
+		load = `echo "Load finished successfully."`
+	}
+
+	script := filepath.Join(GinkgoT().TempDir(), "session")
+	contents := "#!/bin/sh\n" + session.Prelude + "\n" +
+		"case \"$*\" in\n" +
+		"  *EnsLibMain*) " + main + " ;;\n" +
+		"  *Delete*) ;;\n" +
+		"  *) " + load + " ;;\n" +
+		"esac\n"
+	Expect(os.WriteFile(script, []byte(contents), 0755)).To(Succeed())
+	return script
+}
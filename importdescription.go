@@ -19,6 +19,7 @@ package isclib
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -26,6 +27,9 @@ import (
 
 const (
 	importFmtStr = `##class(%%SYSTEM.OBJ).ImportDir("%s","%s","%s",,%d)`
+	loadFmtStr   = `##class(%%SYSTEM.OBJ).Load("%s","%s")`
+	// The longest list of files passed to a single Load command, longer lists are split across several commands
+	maxLoadListLength = 4096
 )
 
 var (
@@ -37,6 +41,11 @@ var (
 	ErrPathAfterRecursiveDirs = errors.New("a ** must only be used as the last portion of the path before the file pattern")
 	// ErrWildcardInDirectory is an error signifying that a wildcard has been included in the directory part of the glob pattern
 	ErrWildcardInDirectory = errors.New("the directory portion of the glob must not contain *")
+	// ErrNoMatchingFiles is an error signifying that no files remained to import after applying the exclusions
+	ErrNoMatchingFiles = errors.New("no files match the import description")
+	// ErrUnsupportedImportPath is an error signifying that a file cannot be loaded because its path contains a comma,
+	// which $SYSTEM.OBJ.Load treats as a separator between files
+	ErrUnsupportedImportPath = errors.New("the path of a file to load must not contain a comma")
 )
 
 // ImportDescription holds information needed for constructing a valid ISC $SYSTEM.OBJ.ImportDir command
@@ -45,6 +54,16 @@ type ImportDescription struct {
 	FilePattern string
 	Recursive   bool
 	Qualifiers  string
+
+	// Exclude is a list of file patterns which should not be imported.
+	// Each pattern is matched against both the file name and the path of the file relative to Dir.
+	Exclude []string
+}
+
+// NewImportDescriptionWithQualifiers creates and returns a new import description based on the provided glob pattern
+// and qualifiers (see NewImportDescription)
+func NewImportDescriptionWithQualifiers(pathGlob string, qualifiers *Qualifiers) (*ImportDescription, error) {
+	return NewImportDescription(pathGlob, qualifiers.String())
 }
 
 // NewImportDescription creates and returns a new import description based on the provided glob pattern and ISC qualifiers
@@ -86,7 +105,8 @@ func NewImportDescription(pathGlob string, qualifiers string) (*ImportDescriptio
 	return glob, nil
 }
 
-// String returns an ISC $SYSTEM.OBJ.ImportDir command as a string
+// String returns an ISC $SYSTEM.OBJ.ImportDir command as a string.
+// ImportDir has no concept of exclusions so the result does not account for Exclude, use Command for that.
 func (i *ImportDescription) String() string {
 	var rec uint16
 	if i.Recursive {
@@ -96,3 +116,121 @@ func (i *ImportDescription) String() string {
 	}
 	return fmt.Sprintf(importFmtStr, i.Dir, i.FilePattern, i.Qualifiers, rec)
 }
+
+// Commands returns the ISC commands which will import the described files, to be run in order.
+// When there are no exclusions this is the single command returned by String.
+// Otherwise, the matching files are enumerated from the local filesystem and loaded by $SYSTEM.OBJ.Load commands, each
+// given a list of files short enough to be passed to a session.  ErrNoMatchingFiles is returned if no files remain
+// after the exclusions and ErrUnsupportedImportPath if the path of a matching file contains a comma.
+func (i *ImportDescription) Commands() ([]string, error) {
+	if len(i.Exclude) == 0 {
+		return []string{i.String()}, nil
+	}
+
+	files, err := i.MatchingFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, ErrNoMatchingFiles
+	}
+
+	commands := make([]string, 0, 1)
+	var list strings.Builder
+	for _, file := range files {
+		if strings.Contains(file, ",") {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedImportPath, file)
+		}
+
+		quoted := objectScriptQuote(file)
+		if list.Len() > 0 && list.Len()+len(quoted)+1 > maxLoadListLength {
+			commands = append(commands, fmt.Sprintf(loadFmtStr, list.String(), objectScriptQuote(i.Qualifiers)))
+			list.Reset()
+		}
+
+		if list.Len() > 0 {
+			list.WriteString(",")
+		}
+		list.WriteString(quoted)
+	}
+
+	return append(commands, fmt.Sprintf(loadFmtStr, list.String(), objectScriptQuote(i.Qualifiers))), nil
+}
+
+// objectScriptQuote escapes the double quotes of a value so it can be placed in an ObjectScript string literal
+func objectScriptQuote(value string) string {
+	return strings.ReplaceAll(value, `"`, `""`)
+}
+
+// MatchingFiles expands the directory and file pattern (honoring Recursive) against the local filesystem.
+// Files matching any of the Exclude patterns are omitted.
+// It returns the paths of the files which would be imported and any error encountered.
+func (i *ImportDescription) MatchingFiles() ([]string, error) {
+	for _, pattern := range append([]string{i.FilePattern}, i.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	files := make([]string, 0)
+	if !i.Recursive {
+		entries, err := os.ReadDir(i.Dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() && i.matches(entry.Name(), entry.Name()) {
+				files = append(files, filepath.Join(i.Dir, entry.Name()))
+			}
+		}
+
+		return files, nil
+	}
+
+	err := filepath.WalkDir(i.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(i.Dir, path)
+		if err != nil {
+			return err
+		}
+
+		if i.matches(d.Name(), rel) {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// the patterns have already been validated so the match errors can be safely ignored
+func (i *ImportDescription) matches(name, rel string) bool {
+	if m, _ := filepath.Match(i.FilePattern, name); !m {
+		return false
+	}
+
+	for _, pattern := range i.Exclude {
+		if m, _ := filepath.Match(pattern, name); m {
+			return false
+		}
+
+		if m, _ := filepath.Match(pattern, rel); m {
+			return false
+		}
+	}
+
+	return true
+}
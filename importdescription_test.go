@@ -17,7 +17,10 @@ limitations under the License.
 package isclib_test
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -97,4 +100,148 @@ var _ = Describe("ImportDescription", func() {
 			Expect(id.String()).To(Equal(`##class(%SYSTEM.OBJ).ImportDir("/a/b/c","abc.xml","/t2",,1)`))
 		})
 	})
+
+	Context("MatchingFiles", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			Expect(os.MkdirAll(filepath.Join(dir, "sub", "deeper"), 0755)).To(Succeed())
+			for _, f := range []string{"a.xml", "b.xml", "c.txt", "sub/d.xml", "sub/deeper/e.xml", "sub/f.txt"} {
+				Expect(os.WriteFile(filepath.Join(dir, f), []byte{}, 0644)).To(Succeed())
+			}
+		})
+
+		It("Returns only the files in the directory when not recursive", func() {
+			id, err := isclib.NewImportDescription(filepath.Join(dir, "*.xml"), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id.MatchingFiles()).To(Equal([]string{
+				filepath.Join(dir, "a.xml"),
+				filepath.Join(dir, "b.xml"),
+			}))
+		})
+
+		It("Returns the files in all subdirectories when recursive", func() {
+			id, err := isclib.NewImportDescription(filepath.Join(dir, "**", "*.xml"), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id.MatchingFiles()).To(Equal([]string{
+				filepath.Join(dir, "a.xml"),
+				filepath.Join(dir, "b.xml"),
+				filepath.Join(dir, "sub", "d.xml"),
+				filepath.Join(dir, "sub", "deeper", "e.xml"),
+			}))
+		})
+
+		It("Returns an empty list when nothing matches", func() {
+			id, err := isclib.NewImportDescription(filepath.Join(dir, "*.mac"), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id.MatchingFiles()).To(BeEmpty())
+		})
+
+		It("Returns an error when the directory does not exist", func() {
+			id, err := isclib.NewImportDescription(filepath.Join(dir, "missing", "*.xml"), "")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = id.MatchingFiles()
+			Expect(err).To(MatchError(os.ErrNotExist))
+		})
+
+		Context("With exclusions", func() {
+			It("Omits files matching an exclusion by name", func() {
+				id, err := isclib.NewImportDescription(filepath.Join(dir, "**", "*.xml"), "")
+				Expect(err).NotTo(HaveOccurred())
+				id.Exclude = []string{"b.xml", "e.*"}
+				Expect(id.MatchingFiles()).To(Equal([]string{
+					filepath.Join(dir, "a.xml"),
+					filepath.Join(dir, "sub", "d.xml"),
+				}))
+			})
+
+			It("Omits files matching an exclusion by relative path", func() {
+				id, err := isclib.NewImportDescription(filepath.Join(dir, "**", "*.xml"), "")
+				Expect(err).NotTo(HaveOccurred())
+				id.Exclude = []string{"sub/*"}
+				Expect(id.MatchingFiles()).To(Equal([]string{
+					filepath.Join(dir, "a.xml"),
+					filepath.Join(dir, "b.xml"),
+					filepath.Join(dir, "sub", "deeper", "e.xml"),
+				}))
+			})
+
+			It("Returns an error for a malformed exclusion", func() {
+				id, err := isclib.NewImportDescription(filepath.Join(dir, "*.xml"), "")
+				Expect(err).NotTo(HaveOccurred())
+				id.Exclude = []string{"["}
+				_, err = id.MatchingFiles()
+				Expect(err).To(MatchError(filepath.ErrBadPattern))
+			})
+		})
+	})
+
+	Context("Command", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			for _, f := range []string{"a.xml", "a_test.xml", "b.xml"} {
+				Expect(os.WriteFile(filepath.Join(dir, f), []byte{}, 0644)).To(Succeed())
+			}
+		})
+
+		It("Uses ImportDir when there are no exclusions", func() {
+			id, err := isclib.NewImportDescription(filepath.Join(dir, "*.xml"), "/t1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id.Commands()).To(Equal([]string{id.String()}))
+		})
+
+		It("Loads the filtered list of files when there are exclusions", func() {
+			id, err := isclib.NewImportDescription(filepath.Join(dir, "*.xml"), "/t1")
+			Expect(err).NotTo(HaveOccurred())
+			id.Exclude = []string{"*_test.xml"}
+			Expect(id.Commands()).To(Equal([]string{`##class(%SYSTEM.OBJ).Load("` + filepath.Join(dir, "a.xml") + "," + filepath.Join(dir, "b.xml") + `","/t1")`}))
+		})
+
+		It("Quotes the paths of the files", func() {
+			Expect(os.WriteFile(filepath.Join(dir, `say "hi".xml`), []byte{}, 0644)).To(Succeed())
+			id, err := isclib.NewImportDescription(filepath.Join(dir, "s*.xml"), "/t1")
+			Expect(err).NotTo(HaveOccurred())
+			id.Exclude = []string{"*_test.xml"}
+			Expect(id.Commands()).To(Equal([]string{`##class(%SYSTEM.OBJ).Load("` + filepath.Join(dir, `say ""hi"".xml`) + `","/t1")`}))
+		})
+
+		It("Splits long lists of files across several commands", func() {
+			big := GinkgoT().TempDir()
+			for n := range 200 {
+				Expect(os.WriteFile(filepath.Join(big, fmt.Sprintf("%s%03d.xml", strings.Repeat("f", 40), n)), []byte{}, 0644)).To(Succeed())
+			}
+			id, err := isclib.NewImportDescription(filepath.Join(big, "*.xml"), "/t1")
+			Expect(err).NotTo(HaveOccurred())
+			id.Exclude = []string{"*_test.xml"}
+			commands, err := id.Commands()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(commands)).To(BeNumerically(">", 1))
+			loaded := 0
+			for _, command := range commands {
+				Expect(len(command)).To(BeNumerically("<", 4200))
+				loaded += strings.Count(command, ".xml")
+			}
+			Expect(loaded).To(Equal(200))
+		})
+
+		It("Returns an error for a path containing a comma", func() {
+			Expect(os.WriteFile(filepath.Join(dir, "c,d.xml"), []byte{}, 0644)).To(Succeed())
+			id, err := isclib.NewImportDescription(filepath.Join(dir, "*.xml"), "/t1")
+			Expect(err).NotTo(HaveOccurred())
+			id.Exclude = []string{"*_test.xml"}
+			_, err = id.Commands()
+			Expect(err).To(MatchError(isclib.ErrUnsupportedImportPath))
+		})
+
+		It("Returns an error when everything is excluded", func() {
+			id, err := isclib.NewImportDescription(filepath.Join(dir, "*.xml"), "/t1")
+			Expect(err).NotTo(HaveOccurred())
+			id.Exclude = []string{"*"}
+			_, err = id.Commands()
+			Expect(err).To(MatchError(isclib.ErrNoMatchingFiles))
+		})
+	})
 })
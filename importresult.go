@@ -0,0 +1,113 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+)
+
+var (
+	// e.g. Imported class: App.Person
+	importedItemRegexp = regexp.MustCompile(`^Imported (?:[A-Za-z ]+): (.+?)\.?$`)
+	// e.g. Loading file /src/App/Person.cls as udl
+	loadedFileRegexp = regexp.MustCompile(`^Loading file (.+) as \w+$`)
+	// e.g. ERROR #5030: An error occurred while compiling class 'App.Person'
+	//      ERROR #5475: Error compiling routine: APPRTN.
+	failedItemRegexps = []*regexp.Regexp{
+		regexp.MustCompile(`An error occurred while compiling (?:[A-Za-z ]+) '([^']+)'`),
+		regexp.MustCompile(`Error compiling (?:[A-Za-z ]+): (.+?)\.?$`),
+	}
+	importErrorRegexp = regexp.MustCompile(`ERROR #\d+:.*$`)
+)
+
+// ImportResult is the result of importing source (see ImportSourceResult)
+type ImportResult struct {
+	// Output is the output of the import
+	Output string
+	// LoadedItems are the items loaded in the order they were reported.  The class/routine names are used when the
+	// import reports them (e.g. XML exports) and the file paths otherwise (e.g. UDL files).
+	LoadedItems []string
+	// FailedItems are the classes/routines which failed to compile in the order they were reported
+	FailedItems []string
+	// Errors are the error lines reported by the import (e.g. ERROR #5030: ...)
+	Errors []string
+}
+
+// ImportSourceResult will import the source specified using a glob pattern (see ImportSource) and parse the output of
+// the import (see ParseImportOutput).
+// It returns the result of the import and any error encountered.  The result is populated whenever the import command
+// was run, including when the load fails (ErrLoadFailed) so the failed items can be inspected.
+func (i *Instance) ImportSourceResult(namespace, sourcePathGlob string, qualifiers ...string) (*ImportResult, error) {
+	qstr := strings.TrimSpace(strings.Join(qualifiers, ""))
+	if qstr == "" {
+		qstr = defaultImportQualifiers
+	}
+
+	id, err := NewImportDescription(sourcePathGlob, qstr)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := i.ImportSourceDescription(namespace, id)
+	return ParseImportOutput(out), err
+}
+
+// ParseImportOutput parses the output of importing source (e.g. from ImportSource) for the items which were loaded and
+// those which failed to compile.
+func ParseImportOutput(output string) *ImportResult {
+	result := &ImportResult{Output: output, LoadedItems: []string{}, FailedItems: []string{}, Errors: []string{}}
+	failed := make(map[string]bool)
+	// the index of the most recently loaded file, it is replaced by the items it contains if they are reported
+	file := -1
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := importedItemRegexp.FindStringSubmatch(line); m != nil {
+			if file >= 0 {
+				result.LoadedItems = result.LoadedItems[:file]
+				file = -1
+			}
+			result.LoadedItems = append(result.LoadedItems, m[1])
+			continue
+		}
+
+		if m := loadedFileRegexp.FindStringSubmatch(line); m != nil {
+			file = len(result.LoadedItems)
+			result.LoadedItems = append(result.LoadedItems, m[1])
+			continue
+		}
+
+		e := importErrorRegexp.FindString(line)
+		if e == "" {
+			continue
+		}
+		result.Errors = append(result.Errors, e)
+
+		for _, re := range failedItemRegexps {
+			if m := re.FindStringSubmatch(e); m != nil && !failed[m[1]] {
+				failed[m[1]] = true
+				result.FailedItems = append(result.FailedItems, m[1])
+				break
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("ImportResult", func() {
+	const failedOutput = `
+Load of directory started on 06/06/2023 14:06:02
+Loading file /src/App/Person.cls as udl
+Loading file /src/export.xml as xml
+Imported class: App.Address
+Imported routine: APPRTN.mac
+Compilation started on 06/06/2023 14:06:02 with qualifiers 'ck'
+Compiling class App.Person
+ERROR #5373: Class 'App.Missing', used by 'App.Person:property:Other', does not exist
+  > ERROR #5030: An error occurred while compiling class 'App.Person'
+Compiling routine APPRTN.1
+ERROR #5475: Error compiling routine: APPRTN.
+Detected 2 errors during compilation in 0.012s.
+`
+
+	Context("ParseImportOutput", func() {
+		It("Reports the loaded and failed items", func() {
+			result := isclib.ParseImportOutput(failedOutput)
+			Expect(result.Output).To(Equal(failedOutput))
+			Expect(result.LoadedItems).To(Equal([]string{"/src/App/Person.cls", "App.Address", "APPRTN.mac"}))
+			Expect(result.FailedItems).To(Equal([]string{"App.Person", "APPRTN"}))
+			Expect(result.Errors).To(Equal([]string{
+				"ERROR #5373: Class 'App.Missing', used by 'App.Person:property:Other', does not exist",
+				"ERROR #5030: An error occurred while compiling class 'App.Person'",
+				"ERROR #5475: Error compiling routine: APPRTN.",
+			}))
+		})
+
+		It("Reports no failures for a successful load", func() {
+			result := isclib.ParseImportOutput("Loading file /src/App/Person.cls as udl\nLoad finished successfully.\n")
+			Expect(result.LoadedItems).To(Equal([]string{"/src/App/Person.cls"}))
+			Expect(result.FailedItems).To(BeEmpty())
+			Expect(result.Errors).To(BeEmpty())
+		})
+	})
+
+	Context("ImportSourceResult", func() {
+		var (
+			instance *isclib.Instance
+			glob     string
+		)
+
+		writeSession := func(output string) {
+			dir := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(dir, "output"), []byte(output), 0644)).To(Succeed())
+			script := filepath.Join(dir, "session")
+			Expect(os.WriteFile(script, []byte("#!/bin/sh\ncat "+filepath.Join(dir, "output")+"\n"), 0755)).To(Succeed())
+			instance.SessionPath = script
+		}
+
+		BeforeEach(func() {
+			src := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(src, "Person.cls"), nil, 0644)).To(Succeed())
+			glob = filepath.Join(src, "*.cls")
+			instance = &isclib.Instance{Name: "INSTTEST"}
+		})
+
+		It("Returns the failed items along with ErrLoadFailed", func() {
+			writeSession(failedOutput)
+			result, err := instance.ImportSourceResult("USER", glob)
+			Expect(err).To(MatchError(isclib.ErrLoadFailed))
+			Expect(result.FailedItems).To(Equal([]string{"App.Person", "APPRTN"}))
+		})
+
+		It("Keeps ImportSource returning the output", func() {
+			writeSession("Loading file /src/App/Person.cls as udl\nLoad finished successfully.\n")
+			Expect(instance.ImportSource("USER", glob)).To(Equal("Loading file /src/App/Person.cls as udl\nLoad finished successfully.\n"))
+		})
+
+		It("Returns no result for an invalid glob", func() {
+			result, err := instance.ImportSourceResult("USER", "/a/**/b/*.cls")
+			Expect(err).To(MatchError(isclib.ErrPathAfterRecursiveDirs))
+			Expect(result).To(BeNil())
+		})
+	})
+
+	Context("Namespace verification", func() {
+		// The fake session lists the USER namespace and records the namespaces source is imported into
+		var (
+			instance *isclib.Instance
+			imports  string
+			glob     string
+		)
+
+		BeforeEach(func() {
+			dir := GinkgoT().TempDir()
+			isclib.SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+			imports = filepath.Join(dir, "imports")
+			script := isclib.WriteFakeSession(isclib.FakeSession{
+				Main: `echo "NAMESPACE:USER"`,
+				Load: `echo "$3" >> ` + imports + `; echo "Load finished successfully."`,
+			})
+			Expect(os.WriteFile(filepath.Join(dir, "Person.cls"), nil, 0644)).To(Succeed())
+			glob = filepath.Join(dir, "*.cls")
+			instance = &isclib.Instance{Name: "INSTTEST", SessionPath: script}
+			instance.SetVerifyImportNamespace(true)
+		})
+		AfterEach(func() {
+			isclib.SetExecuteTemporaryDirectory("")
+		})
+
+		It("Imports into a namespace which exists", func() {
+			_, err := instance.ImportSource("user", glob)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.ReadFile(imports)).To(Equal([]byte("%SYS\nuser\n")))
+		})
+
+		It("Returns ErrNamespaceNotFound without importing into a namespace which does not exist", func() {
+			_, err := instance.ImportSource("USR", glob)
+			Expect(err).To(MatchError(isclib.ErrNamespaceNotFound))
+			Expect(os.ReadFile(imports)).To(Equal([]byte("%SYS\n")))
+		})
+	})
+})
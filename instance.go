@@ -17,18 +17,18 @@ limitations under the License.
 package isclib
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"os"
 	"os/exec"
 	"os/user"
 	"path"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -38,29 +38,68 @@ import (
 )
 
 const (
-	irisKeyName             = "license.key"
-	cacheKeyName            = "cache.key"
-	primaryJournalPattern   = "CurrentDirectory=(.+)"
-	alternateJournalPattern = "AlternateDirectory=(.+)"
-	//regex to remove the [ ,1,,, etc. ] configuration on InterSystems DAT lines
-	extraInfoPattern  = "(1+|,+)"
-	managerUserKey    = "security_settings.manager_user"
-	managerGroupKey   = "security_settings.manager_group"
-	ownerUserKey      = "security_settings.cache_user"
-	ownerGroupKey     = "security_settings.cache_group"
-	irisOwnerUserKey  = "security_settings.iris_user"
-	irisOwnerGroupKey = "security_settings.iris_group"
-	// DefaultImportQualifiers are the default ISC qualifiers used for importing source
+	userNamespace         = "USER"
+	ensembleDemoNamespace = "ENSDEMO"
+	systemNamespace       = "%SYS"
+	irisKeyName           = "license.key"
+	cacheKeyName          = "cache.key"
+	cacheConsoleLogName   = "cconsole.log"
+	irisConsoleLogName    = "messages.log"
+	primaryJournalKey     = "CurrentDirectory"
+	alternateJournalKey   = "AlternateDirectory"
+	managerUserKey        = "security_settings.manager_user"
+	managerGroupKey       = "security_settings.manager_group"
+	ownerUserKey          = "security_settings.cache_user"
+	ownerGroupKey         = "security_settings.cache_group"
+	irisOwnerUserKey      = "security_settings.iris_user"
+	irisOwnerGroupKey     = "security_settings.iris_group"
+	// DefaultImportQualifiers are the initial default ISC qualifiers used for importing source (see SetDefaultImportQualifiers)
 	DefaultImportQualifiers = "/compile/keepsource/expand/multicompile"
 	// CacheDatName is the common name for a Cache database file
 	CacheDatName = "CACHE.DAT"
 	// IrisDatName is the common name for a Iris database file
 	IrisDatName = "IRIS.DAT"
+
+	defaultShutdownTimeout = 300 * time.Second
+	// ISC defaults for the [Journal] settings
+	defaultJournalFileSizeLimitMB    = 1024
+	defaultJournalDaysBeforePurge    = 2
+	defaultJournalBackupsBeforePurge = 2
+	lastUsedActivityPrefix           = "last used "
+	sinceActivityPrefix              = "since "
+	// the number of times a new temporary file name is generated when the name is already in use
+	maxTmpFileAttempts = 10000
 )
 
 var (
 	// ErrLoadFailed is an error signifying that the loading of the source code failed
-	ErrLoadFailed   = errors.New("load did not appear to finish successfully")
+	ErrLoadFailed = errors.New("load did not appear to finish successfully")
+	// ErrSwitchUserNotSupported is an error signifying that commands cannot be run as another user on this platform
+	// (e.g. Windows)
+	ErrSwitchUserNotSupported = errors.New("running commands as another user is not supported on this platform")
+	// ErrInstanceNotFound is an error signifying that qlist does not list an instance with the provided name
+	ErrInstanceNotFound = errors.New("instance not found")
+	// ErrNamespaceNotFound is an error signifying that a namespace is not configured in the instance
+	ErrNamespaceNotFound = errors.New("namespace not found")
+	// ErrDatabaseNotFound is an error signifying that a database is not configured in the instance
+	ErrDatabaseNotFound = errors.New("database not found")
+	// ErrManagerUserNotConfigured is an error signifying that the parameters file does not contain the manager user
+	ErrManagerUserNotConfigured = errors.New("manager user not found in parameters file")
+	// ErrManagerGroupNotConfigured is an error signifying that the parameters file does not contain the manager group
+	ErrManagerGroupNotConfigured = errors.New("manager group not found in parameters file")
+	// ErrOwnerUserNotConfigured is an error signifying that the parameters file does not contain the owner user
+	ErrOwnerUserNotConfigured = errors.New("owner user not found in parameters file")
+	// ErrOwnerGroupNotConfigured is an error signifying that the parameters file does not contain the owner group
+	ErrOwnerGroupNotConfigured = errors.New("owner group not found in parameters file")
+	// ErrRestartStop is an error signifying that a restart failed while stopping the instance
+	ErrRestartStop = errors.New("restart failed stopping instance")
+	// ErrRestartStart is an error signifying that a restart failed while starting the instance
+	ErrRestartStart = errors.New("restart failed starting instance")
+	// ErrUserNotFound is an error signifying that a user does not exist on the system
+	ErrUserNotFound = errors.New("user not found on system")
+	// ErrDataDirectoryMismatch is an error signifying that the instance's CPF does not exist in its data directory
+	ErrDataDirectoryMismatch = errors.New("the CPF does not exist in the data directory")
+
 	getQlist        = qlist
 	parameterReader = fileParameterReader
 )
@@ -68,8 +107,8 @@ var (
 // An Instance represents an instance of Caché/Ensemble/Iris on the current system.
 type Instance struct {
 	// Required to be able to run the executor
-	SessionPath string `json:"-"` // The path to the session executable
-	ControlPath string `json:"-"` // The path to the control executable
+	SessionPath string `json:"-"` // The path to the session executable (environment variables are expanded when run)
+	ControlPath string `json:"-"` // The path to the control executable (environment variables are expanded when run)
 
 	// These values come directly from qlist
 	Name             string         `json:"name"`             // The name of the instance
@@ -83,11 +122,26 @@ type Instance struct {
 	JDBCPort         int            `json:"jdbcPort"`         // The JDBC port
 	State            string         `json:"state"`            // The State of the instance (warn, etc.)
 	Product          Product        `json:"product"`          // The product name of the instance
+	Edition          Edition        `json:"edition"`          // The edition of the product (IRIS for Health, etc.), empty for Cache/Ensemble
 	MirrorMemberType string         `json:"mirrorMemberType"` // The mirror member type (Failover, Disaster Recovery, etc)
 	MirrorStatus     string         `json:"mirrorStatus"`     // The mirror Status (Primary, Backup, Connected, etc.)
 	DataDirectory    string         `json:"dataDirectory"`    //  The instance data directory.  This might be the same as Directory if durable %SYS isn't in use
 
 	executionSysProcAttr *syscall.SysProcAttr // This is used internally to allow execution of Caché code as different users
+	sessionCredentials   *sessionCredentials  // This is used internally to log in to instances requiring authentication
+	executeRetry         *RetryPolicy         // This is used internally to retry executions failing with transient errors
+	controlRetry         *RetryPolicy         // This is used internally to retry failing control commands
+	signOnSession        *terminalSession     // This is used internally to allow sign-ons through the session which inhibited them
+	ioTranslation        string               // This is used internally to set the I/O translation of executed code
+	verifyImportNS       bool                 // This is used internally to check the namespace exists before importing source
+	baseSysProcAttr      *syscall.SysProcAttr // This is used internally to start every command with additional process attributes
+	webServerTLS         bool                 // This is used internally to build https URLs for a web server port serving TLS
+}
+
+// sessionCredentials are provided to the session on standard input in response to its login prompts
+type sessionCredentials struct {
+	username string
+	password string
 }
 
 // Update will query the underlying instance and update the Instance fields with its current state.
@@ -100,7 +154,7 @@ func (i *Instance) Update() error {
 
 	// if we didn't get a manager proc, try to update without it to find the manager
 	if procAttr == nil {
-		q, err := getQlist(i.Name, nil)
+		q, err := i.instanceQList(nil)
 		if err != nil {
 			return err
 		}
@@ -115,7 +169,7 @@ func (i *Instance) Update() error {
 		}
 	}
 
-	q, err := getQlist(i.Name, procAttr)
+	q, err := i.instanceQList(procAttr)
 	if err != nil {
 		return err
 	}
@@ -123,10 +177,35 @@ func (i *Instance) Update() error {
 	return i.UpdateFromQList(q)
 }
 
+// instanceQList returns the line of the instance's qlist which describes the instance.
+// It returns the line and any error encountered, including ErrInstanceNotFound if no line describes the instance.
+func (i *Instance) instanceQList(procAttr *syscall.SysProcAttr) (string, error) {
+	q, err := getQlist(i.Name, i.commandSysProcAttr(procAttr))
+	if err != nil {
+		return "", err
+	}
+
+	if line, ok := qlistLine(q, i.Name); ok {
+		return line, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrInstanceNotFound, i.Name)
+}
+
 // UpdateFromQList will update the current Instance with the values from the qlist string.
+// If lenient qlist parsing is enabled (see SetLenientQList) the qlist is parsed by UpdateFromQListLenient and any
+// warnings are logged rather than returned.
 // It returns any error encountered.
 func (i *Instance) UpdateFromQList(qlist string) (err error) {
-	qs := strings.Split(qlist, "^")
+	if lenientQList {
+		warnings, err := i.UpdateFromQListLenient(qlist)
+		for _, w := range warnings {
+			log.WithFields(log.Fields{"instance": i.Name, "qlist": qlist}).Warn(w)
+		}
+		return err
+	}
+
+	qs := strings.Split(qlist, qlistDelimiter)
 	if len(qs) < 8 {
 		return fmt.Errorf("insufficient pieces in qlist, need at least 8, qlist: %s", qlist)
 	}
@@ -143,6 +222,51 @@ func (i *Instance) UpdateFromQList(qlist string) (err error) {
 		return err
 	}
 
+	i.updateFromQListPieces(qs)
+	return nil
+}
+
+// UpdateFromQListLenient will update the current Instance with whatever values are present in the qlist string.
+// Unlike UpdateFromQList, a qlist with fewer than the 8 standard pieces (e.g. from very old versions) or with ports
+// which are not numbers is accepted.  The values which are missing or invalid are left zero-valued.
+// It returns a warning for each value which could not be populated and an error only if the qlist does not contain an
+// instance name.
+func (i *Instance) UpdateFromQListLenient(qlist string) ([]string, error) {
+	qs := strings.Split(qlist, qlistDelimiter)
+	if strings.TrimSpace(qs[0]) == "" {
+		return nil, fmt.Errorf("qlist does not contain an instance name, qlist: %s", qlist)
+	}
+
+	var warnings []string
+	if len(qs) < 8 {
+		warnings = append(warnings, fmt.Sprintf("insufficient pieces in qlist, need at least 8, found %d", len(qs)))
+		qs = append(qs, make([]string, 8-len(qs))...)
+	}
+
+	ports := []struct {
+		name  string
+		piece string
+		port  *int
+	}{
+		{"SuperServer", qs[5], &i.SuperServerPort},
+		{"WebServer", qs[6], &i.WebServerPort},
+		{"JDBC", qs[7], &i.JDBCPort},
+	}
+	for _, p := range ports {
+		n, err := strconv.Atoi(p.piece)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("invalid %s port in qlist: %q", p.name, p.piece))
+			n = 0
+		}
+		*p.port = n
+	}
+
+	i.updateFromQListPieces(qs)
+	return warnings, nil
+}
+
+// updateFromQListPieces updates the non-port values of the instance from a qlist split into at least 8 pieces
+func (i *Instance) updateFromQListPieces(qs []string) {
 	i.Name = qs[0]
 	i.Directory = qs[1]
 	i.DataDirectory = i.Directory
@@ -161,21 +285,19 @@ func (i *Instance) UpdateFromQList(qlist string) (err error) {
 		// It could be that the value won't match any of the known product strings we check in which case you would have the product reported as Cache
 		productString = qs[9]
 	}
-	i.Product = i.determineProduct(productString)
+	i.Product, i.Edition = i.determineProduct(productString)
 
 	if len(qs) >= 11 {
-		i.MirrorMemberType = qs[10]
+		i.MirrorMemberType = parseMirrorMemberType(qs[10])
 	}
 
 	if len(qs) >= 12 {
-		i.MirrorStatus = qs[11]
+		i.MirrorStatus = string(parseMirrorStatus(qs[11]))
 	}
 
 	if len(qs) >= 13 && qs[12] != "" {
 		i.DataDirectory = qs[12]
 	}
-
-	return nil
 }
 
 // Dat holds information that pertains an existing ISC database
@@ -187,69 +309,231 @@ type Dat struct {
 	Exists     bool
 }
 
-// DatInfo will parse the instance's CPF file for its databases (CACHE.DAT, IRIS.DAT).
-// It will get the path of the InterSystems DAT file, the permissions on it, and its owning user / group.
-// The function returns a map of Dat structs containing the above information using the name of the database as its key.
-func (i *Instance) DatInfo() (map[string]Dat, error) {
-	cpfPath := filepath.Join(i.DataDirectory, i.CPFFileName)
-	file, err := os.Open(cpfPath)
+// CPFFilePath returns the path to the CPF file used by this instance at startup.
+// The CPF lives in the data directory so it honors durable %SYS.
+func (i *Instance) CPFFilePath() string {
+	return filepath.Join(i.DataDirectory, i.CPFFileName)
+}
+
+// ValidateDataDirectory will ensure the instance's CPF exists in its data directory (see CPFFilePath).
+// When the data directory reported by qlist does not match where the CPF actually is (e.g. a misconfigured durable
+// %SYS), every method using the CPF fails, this reports the mismatch once with both directories.
+// It returns an error wrapping ErrDataDirectoryMismatch if the CPF does not exist or any other error encountered.
+func (i *Instance) ValidateDataDirectory() error {
+	p := i.CPFFilePath()
+	info, err := os.Stat(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w, cpf: %s, directory: %s, data directory: %s", ErrDataDirectoryMismatch, p, i.Directory, i.DataDirectory)
+		}
+		return fmt.Errorf("unable to validate data directory: %w", err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("%w, cpf is a directory: %s, directory: %s, data directory: %s", ErrDataDirectoryMismatch, p, i.Directory, i.DataDirectory)
+	}
+
+	return nil
+}
+
+// CPFFiles returns the names of all of the CPF files (profiles) in the instance's data directory.
+// The active CPF is the one named by CPFFileName.
+func (i *Instance) CPFFiles() ([]string, error) {
+	entries, err := os.ReadDir(i.DataDirectory)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-	var inDbSection bool
-	var dats = make(map[string]Dat)
-	re := regexp.MustCompile(extraInfoPattern)
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := re.ReplaceAllString(scanner.Text(), "")
+	cpfs := make([]string, 0)
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.EqualFold(filepath.Ext(entry.Name()), ".cpf") {
+			cpfs = append(cpfs, entry.Name())
+		}
+	}
+
+	return cpfs, nil
+}
 
-		if inDbSection {
-			if strings.TrimSpace(line) == "" {
-				break
-			}
-			splitLine := strings.Split(line, "=")
-			iscDatPath := splitLine[1] + i.DetermineISCDatFileName()
-			iscDat := Dat{Path: splitLine[1], Exists: true}
-			datFileInfo, err := os.Stat(iscDatPath)
+// StartupSettings holds the timing related settings from the [Startup] section of the CPF
+type StartupSettings struct {
+	ShutdownTimeout time.Duration // The time the instance is given to shut down before processes are forced to halt
+}
+
+// StartupSettings will parse the instance's CPF file for its [Startup] timing settings.
+// Settings missing from the CPF are given the ISC default values.
+func (i *Instance) StartupSettings() (StartupSettings, error) {
+	settings := StartupSettings{ShutdownTimeout: defaultShutdownTimeout}
+	values, err := i.cpfSection("Startup")
+	if err != nil {
+		return settings, err
+	}
+
+	if v, ok := values["ShutdownTimeout"]; ok && v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil {
+			return settings, fmt.Errorf("invalid ShutdownTimeout in CPF: %w", err)
+		}
+		settings.ShutdownTimeout = time.Duration(seconds) * time.Second
+	}
+
+	return settings, nil
+}
+
+// JournalSettings holds the journal file settings from the [Journal] section of the CPF.
+// Switching journal files on a schedule is configured as a Task Manager task rather than in the CPF, so the settings
+// only describe when a journal file switches due to its size and how long journal files are kept.
+type JournalSettings struct {
+	FileSizeLimit      int64  // The size in bytes at which the instance switches to a new journal file
+	DaysBeforePurge    int    // The number of days journal files are kept before being purged (0 disables)
+	BackupsBeforePurge int    // The number of successful backups journal files are kept for before being purged (0 disables)
+	FreezeOnError      bool   // Whether the instance freezes when journaling fails rather than continuing without it
+	CompressFiles      bool   // Whether journal files are compressed once they are no longer current
+	FilePrefix         string // The prefix added to the names of journal files
+}
+
+// JournalSettings will parse the instance's CPF file for its [Journal] file settings.
+// Settings missing from the CPF are given the ISC default values.
+func (i *Instance) JournalSettings() (JournalSettings, error) {
+	settings := JournalSettings{
+		FileSizeLimit:      defaultJournalFileSizeLimitMB * 1024 * 1024,
+		DaysBeforePurge:    defaultJournalDaysBeforePurge,
+		BackupsBeforePurge: defaultJournalBackupsBeforePurge,
+		CompressFiles:      true,
+	}
+	values, err := i.cpfSection("Journal")
+	if err != nil {
+		return settings, err
+	}
+
+	ints := []struct {
+		key   string
+		value *int
+	}{
+		{"DaysBeforePurge", &settings.DaysBeforePurge},
+		{"BackupsBeforePurge", &settings.BackupsBeforePurge},
+	}
+	for _, setting := range ints {
+		if v, ok := values[setting.key]; ok && v != "" {
+			n, err := strconv.Atoi(v)
 			if err != nil {
-				if os.IsNotExist(err) {
-					iscDat.Exists = false
-				} else {
-					return nil, err
-				}
-			} else {
-				fileOwner, err := user.LookupId(fmt.Sprint(datFileInfo.Sys().(*syscall.Stat_t).Uid))
-				if err != nil {
-					return nil, err
-				}
-				iscDat.Owner = fileOwner.Username
-				fileGroup, err := user.LookupGroupId(fmt.Sprint(datFileInfo.Sys().(*syscall.Stat_t).Gid))
-				if err != nil {
-					return nil, err
-				}
-				iscDat.Group = fileGroup.Name
-				iscDat.Permission = datFileInfo.Mode().String()
+				return settings, fmt.Errorf("invalid %s in CPF: %w", setting.key, err)
 			}
-			dats[splitLine[0]] = iscDat
-		} else if line == "[Databases]" {
-			inDbSection = true
+			*setting.value = n
+		}
+	}
+
+	if v, ok := values["FileSizeLimit"]; ok && v != "" {
+		mb, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return settings, fmt.Errorf("invalid FileSizeLimit in CPF: %w", err)
 		}
+		settings.FileSizeLimit = mb * 1024 * 1024
 	}
 
-	if err := scanner.Err(); err != nil {
+	if v, ok := values["FreezeOnError"]; ok && v != "" {
+		settings.FreezeOnError = v != "0"
+	}
+
+	if v, ok := values["CompressFiles"]; ok && v != "" {
+		settings.CompressFiles = v != "0"
+	}
+
+	settings.FilePrefix = values["JournalFilePrefix"]
+
+	return settings, nil
+}
+
+// DatInfo will parse the instance's CPF file for its databases (CACHE.DAT, IRIS.DAT).
+// It will get the path of the InterSystems DAT file, the permissions on it, and its owning user / group.
+// The function returns a map of Dat structs containing the above information using the name of the database as its key.
+// See Databases for the databases in the order they are configured.
+func (i *Instance) DatInfo() (map[string]Dat, error) {
+	dbs, err := i.Databases()
+	if err != nil {
 		return nil, err
 	}
 
+	var dats = make(map[string]Dat, len(dbs))
+	for _, db := range dbs {
+		dats[db.Name] = Dat{
+			Path:       db.Directory,
+			Permission: db.Permission,
+			Owner:      db.Owner,
+			Group:      db.Group,
+			Exists:     db.Exists,
+		}
+	}
+
 	return dats, nil
 }
 
+// cpfDatabaseDirectory returns the directory of a [Databases] value, dropping the additional comma separated settings
+// (e.g. the ,1,,, of /data/app/,1,,,)
+func cpfDatabaseDirectory(value string) string {
+	directory, _, _ := strings.Cut(value, ",")
+	return directory
+}
+
+// NamespaceMapping holds the default databases for a namespace
+type NamespaceMapping struct {
+	Namespace        string `json:"namespace"`
+	GlobalsDatabase  string `json:"globalsDatabase"`
+	RoutinesDatabase string `json:"routinesDatabase"`
+}
+
+// NamespaceMappings will parse the instance's CPF file for its namespaces and their default databases.
+// The function returns a map of NamespaceMapping structs using the name of the namespace as its key.
+func (i *Instance) NamespaceMappings() (map[string]NamespaceMapping, error) {
+	values, err := i.cpfSection("Namespaces")
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := make(map[string]NamespaceMapping, len(values))
+	for namespace, value := range values {
+		dbs := strings.Split(value, ",")
+		mapping := NamespaceMapping{Namespace: namespace, GlobalsDatabase: dbs[0], RoutinesDatabase: dbs[0]}
+		if len(dbs) > 1 && dbs[1] != "" {
+			mapping.RoutinesDatabase = dbs[1]
+		}
+		mappings[namespace] = mapping
+	}
+
+	return mappings, nil
+}
+
+// NamespaceGlobalsDat will determine the database holding the globals of the provided namespace by default.
+// The namespace name is case-insensitive.
+// It returns the Dat for the database and any error encountered.
+func (i *Instance) NamespaceGlobalsDat(namespace string) (Dat, error) {
+	mappings, err := i.NamespaceMappings()
+	if err != nil {
+		return Dat{}, err
+	}
+
+	mapping, ok := mappings[strings.ToUpper(namespace)]
+	if !ok {
+		return Dat{}, fmt.Errorf("%w: %s", ErrNamespaceNotFound, namespace)
+	}
+
+	dats, err := i.DatInfo()
+	if err != nil {
+		return Dat{}, err
+	}
+
+	dat, ok := dats[mapping.GlobalsDatabase]
+	if !ok {
+		return Dat{}, fmt.Errorf("%w: %s (globals for namespace %s)", ErrDatabaseNotFound, mapping.GlobalsDatabase, namespace)
+	}
+
+	return dat, nil
+}
+
 // DetermineManager will determine the manager of an instance by reading the parameters file associated with this instance.
 // The manager is the primary user of the instance that will be able to perform start/stop operations etc.
 // It returns the manager and manager group as strings and any error encountered.
 func (i *Instance) DetermineManager() (string, string, error) {
-	return i.getUserAndGroupFromParameters("Manager", managerUserKey, managerGroupKey)
+	return i.getUserAndGroupFromParameters(managerUserKey, managerGroupKey, ErrManagerUserNotConfigured, ErrManagerGroupNotConfigured)
 }
 
 // managerSysProc is used to run instance management commands as a different user (if the current user isn't the manager)
@@ -271,14 +555,19 @@ func (i *Instance) managerSysProc() (*syscall.SysProcAttr, error) {
 
 	sysProcAttr, err := switchUserSysProc(mgr)
 	if err != nil {
+		// without the ability to switch users, management commands are run as the current user
+		if errors.Is(err, ErrSwitchUserNotSupported) {
+			log.WithError(err).Debug("cannot run as the manager")
+			return nil, nil
+		}
 		return nil, err
 	}
 
-	if sysProcAttr != nil && sysProcAttr.Credential != nil {
+	if uid, gid, ok := sysProcCredential(sysProcAttr); ok {
 		log.WithFields(log.Fields{
 			"user": mgr,
-			"uid":  sysProcAttr.Credential.Uid,
-			"gid":  sysProcAttr.Credential.Gid,
+			"uid":  uid,
+			"gid":  gid,
 		}).Debug("instance manager sysproc")
 	}
 	return sysProcAttr, nil
@@ -290,68 +579,33 @@ func (i *Instance) managerSysProc() (*syscall.SysProcAttr, error) {
 func (i *Instance) DetermineOwner() (string, string, error) {
 	switch i.Product {
 	case Iris:
-		return i.getUserAndGroupFromParameters("Owner", irisOwnerUserKey, irisOwnerGroupKey)
+		return i.getUserAndGroupFromParameters(irisOwnerUserKey, irisOwnerGroupKey, ErrOwnerUserNotConfigured, ErrOwnerGroupNotConfigured)
 	default:
-		return i.getUserAndGroupFromParameters("Owner", ownerUserKey, ownerGroupKey)
+		return i.getUserAndGroupFromParameters(ownerUserKey, ownerGroupKey, ErrOwnerUserNotConfigured, ErrOwnerGroupNotConfigured)
 	}
 }
 
 // DeterminePrimaryJournalDirectory will parse the ISC instance's CPF file for its primary journal directory (CurrentDirectory).
 func (i *Instance) DeterminePrimaryJournalDirectory() (string, error) {
-	cpfPath := filepath.Join(i.DataDirectory, i.CPFFileName)
-	file, err := os.Open(cpfPath)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	re, err := regexp.Compile(primaryJournalPattern)
-	if err != nil {
-		return "", err
-	}
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		matches := re.FindStringSubmatch(scanner.Text())
-		if len(matches) > 0 {
-			return matches[1], nil
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return "", err
-	}
-
-	return "", fmt.Errorf("unable to determine primary journal directory")
+	return i.journalDirectory(primaryJournalKey, "primary")
 }
 
 // DetermineSecondaryJournalDirectory will parse the ISC instance's CPF file for its secondary journal directory (AlternateDirectory).
 func (i *Instance) DetermineSecondaryJournalDirectory() (string, error) {
-	cpfPath := filepath.Join(i.DataDirectory, i.CPFFileName)
-	file, err := os.Open(cpfPath)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
+	return i.journalDirectory(alternateJournalKey, "secondary")
+}
 
-	re, err := regexp.Compile(alternateJournalPattern)
+func (i *Instance) journalDirectory(key, description string) (string, error) {
+	cpf, err := i.LoadCPF()
 	if err != nil {
 		return "", err
 	}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		matches := re.FindStringSubmatch(scanner.Text())
-		if len(matches) > 0 {
-			return matches[1], nil
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return "", err
+	if directory, ok := cpf.Value("Journal", key); ok && directory != "" {
+		return directory, nil
 	}
 
-	return "", fmt.Errorf("unable to determine secondary journal directory")
+	return "", fmt.Errorf("unable to determine %s journal directory", description)
 }
 
 // DetermineISCDatFileName returns the filename of the InterSystems DAT files used by the instance
@@ -364,30 +618,112 @@ func (i *Instance) DetermineISCDatFileName() string {
 	}
 }
 
+// BinDirectory returns the directory containing the binaries of the instance
+func (i *Instance) BinDirectory() string {
+	return filepath.Join(i.Directory, "bin")
+}
+
+// LibDirectory returns the directory containing the shared libraries of the instance
+func (i *Instance) LibDirectory() string {
+	return filepath.Join(i.Directory, "lib")
+}
+
+// CSPDirectory returns the directory containing the CSP (web application) files of the instance
+func (i *Instance) CSPDirectory() string {
+	return filepath.Join(i.Directory, "csp")
+}
+
+// MgrDirectory returns the manager directory of the instance.
+// This is within the data directory so it honors durable %SYS.
+func (i *Instance) MgrDirectory() string {
+	return filepath.Join(i.DataDirectory, "mgr")
+}
+
 // LicenseKeyFilePath returns the file path to the license key for the instance
 func (i *Instance) LicenseKeyFilePath() string {
 	switch i.Product {
 	case Iris:
-		return filepath.Join(i.DataDirectory, "mgr", irisKeyName)
+		return filepath.Join(i.MgrDirectory(), irisKeyName)
 	default:
-		return filepath.Join(i.DataDirectory, "mgr", cacheKeyName)
+		return filepath.Join(i.MgrDirectory(), cacheKeyName)
+	}
+}
+
+// ConsoleLogPath returns the file path to the console log for the instance.
+// The console log was renamed from cconsole.log to messages.log, so each candidate is checked for existence in the
+// mgr directory starting with the name used by the instance's product.
+// It returns the path of the existing console log and any error encountered.
+func (i *Instance) ConsoleLogPath() (string, error) {
+	candidates := []string{cacheConsoleLogName, irisConsoleLogName}
+	if i.Product == Iris {
+		candidates = []string{irisConsoleLogName, cacheConsoleLogName}
+	}
+
+	for _, candidate := range candidates {
+		p := filepath.Join(i.MgrDirectory(), candidate)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
 	}
+
+	return "", fmt.Errorf("console log not found in %s: %w", i.MgrDirectory(), os.ErrNotExist)
 }
 
 // Start will ensure that an instance is started.
+// The control command is bounded by the default command timeout (see SetDefaultCommandTimeout).
 // It returns any error encountered when attempting to start the instance.
 func (i *Instance) Start() error {
+	ctx, cancel := commandContext()
+	defer cancel()
+	return i.StartContext(ctx)
+}
+
+// StartContext will ensure that an instance is started.
+// The control command is killed if ctx is done before the instance has started.
+// It returns any error encountered when attempting to start the instance.
+func (i *Instance) StartContext(ctx context.Context) error {
+	return i.start(ctx, "")
+}
+
+// StartWithCPF will ensure that an instance is started using the provided CPF rather than the active CPF.
+// cpfName can be the name of a CPF in the data directory (see CPFFiles) or a full path to a CPF.
+// If the instance is already running it is not restarted.
+// It returns any error encountered when attempting to start the instance.
+func (i *Instance) StartWithCPF(cpfName string) error {
+	if cpfName == "" {
+		return fmt.Errorf("a CPF must be provided")
+	}
+
+	cpfPath := cpfName
+	if !filepath.IsAbs(cpfPath) {
+		cpfPath = filepath.Join(i.DataDirectory, cpfName)
+	}
+
+	if _, err := os.Stat(cpfPath); err != nil {
+		return fmt.Errorf("unable to use CPF, error: %w", err)
+	}
+
+	ctx, cancel := commandContext()
+	defer cancel()
+	return i.start(ctx, cpfPath)
+}
+
+func (i *Instance) start(ctx context.Context, cpfPath string) error {
 	// TODO: Think about a nozstu flag if there's a reason
 	if i.Status.Down() {
-		cmd := exec.Command(i.controlPath(), "start", i.Name, "quietly")
+		args := []string{"start", i.Name}
+		if cpfPath != "" {
+			args = append(args, cpfPath)
+		}
+		args = append(args, "quietly")
 		procAttr, err := i.managerSysProc()
 		if err != nil {
 			return err
 		}
 
-		cmd.SysProcAttr = procAttr
-		if output, err := cmd.CombinedOutput(); err != nil {
-			log.WithError(err).WithFields(log.Fields{"output": string(output), "instance": i.Name}).Debug("Error start quietly")
+		if _, err := i.runControlContext(ctx, procAttr, args...); err != nil {
 			return fmt.Errorf("error starting instance, error: %w", err)
 		}
 	}
@@ -404,8 +740,18 @@ func (i *Instance) Start() error {
 }
 
 // Stop will ensure that an instance is started.
+// The control command is bounded by the default command timeout (see SetDefaultCommandTimeout).
 // It returns any error encountered when attempting to stop the instance.
 func (i *Instance) Stop() error {
+	ctx, cancel := commandContext()
+	defer cancel()
+	return i.StopContext(ctx)
+}
+
+// StopContext will ensure that an instance is stopped.
+// The control command is killed if ctx is done before the instance has stopped (e.g. a hung shutdown).
+// It returns any error encountered when attempting to stop the instance.
+func (i *Instance) StopContext(ctx context.Context) error {
 	ilog := log.WithField("name", i.Name)
 	ilog.Debug("Shutting down instance")
 	if i.Status.Up() {
@@ -414,14 +760,12 @@ func (i *Instance) Stop() error {
 			args = append(args, "bypass")
 		}
 		args = append(args, "quietly")
-		cmd := exec.Command(i.controlPath(), args...)
 		procAttr, err := i.managerSysProc()
 		if err != nil {
 			return err
 		}
-		cmd.SysProcAttr = procAttr
-		if output, err := cmd.CombinedOutput(); err != nil {
-			ilog.WithError(err).WithFields(log.Fields{"output": string(output), "args": args}).Debug("Error stopping")
+
+		if _, err := i.runControlContext(ctx, procAttr, args...); err != nil {
 			return fmt.Errorf("error stopping instance, error: %w", err)
 		}
 	}
@@ -437,6 +781,111 @@ func (i *Instance) Stop() error {
 	return nil
 }
 
+// Restart will stop the instance (see Stop) and then start it again (see Start).
+// The instance must be down before it is started again and ready once it has started.
+// It returns an error wrapping ErrRestartStop or ErrRestartStart indicating which phase of the restart failed.
+func (i *Instance) Restart() error {
+	log.WithField("name", i.Name).Debug("Restarting instance")
+	if err := i.Stop(); err != nil {
+		return fmt.Errorf("%w: %w", ErrRestartStop, err)
+	}
+
+	if err := i.Start(); err != nil {
+		return fmt.Errorf("%w: %w", ErrRestartStart, err)
+	}
+
+	return nil
+}
+
+// Stat will run the control command's stat subcommand (cstat/irisstat) against the instance to collect diagnostic
+// information.  Additional stat options (e.g. "-e1") may be provided.
+// The command is run as the instance manager when possible.
+// It returns the output of the command and any error encountered.
+func (i *Instance) Stat(options ...string) (string, error) {
+	return i.RunControlCommand(append([]string{"stat"}, options...)...)
+}
+
+// RunControlCommand will run a control command subcommand (e.g. "stat" or "list") which isclib does not explicitly wrap
+// against the instance.  The first argument is the subcommand and the instance name is provided directly after it
+// followed by any remaining arguments (e.g. RunControlCommand("stop", "quietly") runs "iris stop NAME quietly").
+// The arguments are passed directly to the control executable without a shell so they are never interpreted.
+// The command is run as the instance manager when possible.
+// It returns the combined output of the command and any error encountered.
+func (i *Instance) RunControlCommand(args ...string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("a control subcommand must be provided")
+	}
+
+	return i.runControl(append([]string{args[0], i.Name}, args[1:]...)...)
+}
+
+// runControl runs the control command with the provided arguments as the instance manager when possible.
+// It returns the combined output of the command and any error encountered.
+func (i *Instance) runControl(args ...string) (string, error) {
+	ctx, cancel := commandContext()
+	defer cancel()
+	procAttr, err := i.managerSysProc()
+	if err != nil {
+		return "", err
+	}
+
+	output, err := i.runControlContext(ctx, procAttr, args...)
+	if err != nil {
+		return output, fmt.Errorf("error running %s, error: %w", args[0], err)
+	}
+
+	return output, nil
+}
+
+// runControlContext runs the control command with the provided arguments and process attributes, killing it when ctx
+// is done.  A failing command is retried according to the control retry policy, if one is configured (see
+// SetControlRetry).
+// It returns the combined output of the last attempt and any error encountered.
+func (i *Instance) runControlContext(ctx context.Context, procAttr *syscall.SysProcAttr, args ...string) (string, error) {
+	var output []byte
+	run := func() error {
+		cmd := exec.CommandContext(ctx, i.controlPath(), args...)
+		cmd.SysProcAttr = i.commandSysProcAttr(procAttr)
+		cmd.Env = commandEnv()
+		var err error
+		output, err = cmd.CombinedOutput()
+		if err = commandContextError(ctx, err); err != nil {
+			log.WithError(err).WithFields(log.Fields{"output": string(output), "instance": i.Name, "args": args}).Debug("Error running control command")
+		}
+		return err
+	}
+
+	if i.controlRetry == nil {
+		err := run()
+		return string(output), err
+	}
+
+	err := i.controlRetry.Retry(func() error {
+		err := run()
+		if ctx.Err() != nil {
+			// the command was killed, running it again would be killed too
+			return StopRetrying(err)
+		}
+		return err
+	})
+	return string(output), err
+}
+
+// SetControlRetry will configure the instance to retry the control commands it runs (starting and stopping the
+// instance and RunControlCommand) when they fail, according to the provided policy (see RetryPolicy).  This rides out
+// failures which clear up on their own, such as another control command holding the instance's lock.  Only the control
+// command is retried, the status of the instance is checked once it succeeds.
+func (i *Instance) SetControlRetry(policy RetryPolicy) {
+	log.WithFields(log.Fields{"attempts": policy.Attempts, "backoff": policy.Backoff, "maxBackoff": policy.MaxBackoff, "jitter": policy.Jitter}).Debug("Configured control retry")
+	i.controlRetry = &policy
+}
+
+// ClearControlRetry will configure the instance to run all future control commands only once.
+func (i *Instance) ClearControlRetry() {
+	log.Debug("Removing control retry")
+	i.controlRetry = nil
+}
+
 // ExecuteAsCurrentUser will configure the instance to execute all future commands as the current user.
 // It returns any error encountered.
 func (i *Instance) ExecuteAsCurrentUser() error {
@@ -459,29 +908,120 @@ func (i *Instance) ExecuteAsManager() error {
 
 // ExecuteAsUser will configure the instance to execute all future commands as the provided user.
 // This command only functions if the calling program is running as root.
-// It returns any error encountered.
+// It returns any error encountered, including ErrSwitchUserNotSupported on platforms which cannot run commands as
+// another user (e.g. Windows) unless the provided user is the current user.
 func (i *Instance) ExecuteAsUser(execUser string) error {
 	sysProcAttr, err := switchUserSysProc(execUser)
 	if err != nil {
 		return err
 	}
-	if sysProcAttr != nil && sysProcAttr.Credential != nil {
+	if uid, gid, ok := sysProcCredential(sysProcAttr); ok {
 		log.WithFields(log.Fields{
 			"user": execUser,
-			"uid":  sysProcAttr.Credential.Uid,
-			"gid":  sysProcAttr.Credential.Gid,
+			"uid":  uid,
+			"gid":  gid,
 		}).Debug("Configured to execute as alternate user")
 	}
 	i.executionSysProcAttr = sysProcAttr
 	return nil
 }
 
+// SetSessionCredentials will configure the instance to log in to all future session commands with the provided credentials.
+// This is required when the instance's %Service_Terminal requires authentication.
+// The credentials are provided on the standard input of the session in response to its login prompts rather than
+// on the command line so they are not visible in the process list.
+// Credentials should not be configured for instances which do not prompt for them.
+func (i *Instance) SetSessionCredentials(username, password string) {
+	log.WithField("username", username).Debug("Configured session credentials")
+	i.sessionCredentials = &sessionCredentials{username: username, password: password}
+}
+
+// ClearSessionCredentials will configure the instance to run all future session commands without logging in.
+func (i *Instance) ClearSessionCredentials() {
+	log.Debug("Removing session credentials")
+	i.sessionCredentials = nil
+}
+
+// SetSessionIOTranslation will configure the instance to switch the principal device of all future executions (see
+// Execute) to the provided I/O translation table (e.g. "UTF8" or "Latin1") before running the code.
+// This allows the output to be written in a known encoding regardless of the locale the instance is configured with.
+// An empty table restores the instance's default translation.
+// It returns ErrInvalidIOTranslation if the table is not a valid table name.
+func (i *Instance) SetSessionIOTranslation(table string) error {
+	if table != "" && !ioTranslationRegexp.MatchString(table) {
+		return fmt.Errorf("%w: %q", ErrInvalidIOTranslation, table)
+	}
+
+	log.WithField("table", table).Debug("Configured session I/O translation")
+	i.ioTranslation = table
+	return nil
+}
+
+// SessionIOTranslation returns the I/O translation table configured for executions, an empty string means the
+// instance's default translation is used.
+func (i *Instance) SessionIOTranslation() string {
+	return i.ioTranslation
+}
+
+// SetBaseSysProcAttr will configure the instance to start all future session and control commands with the provided
+// process attributes (e.g. Setsid, Chroot or AmbientCaps).  When the command is run as another user (see
+// ExecuteAsUser and the instance manager), the credential for that user replaces any credential in the attributes.
+// The attributes are copied for each command.  nil removes the attributes.
+func (i *Instance) SetBaseSysProcAttr(attr *syscall.SysProcAttr) {
+	log.WithField("attr", attr).Debug("Configured base process attributes")
+	i.baseSysProcAttr = attr
+}
+
+// BaseSysProcAttr returns the process attributes all commands are started with (see SetBaseSysProcAttr)
+func (i *Instance) BaseSysProcAttr() *syscall.SysProcAttr {
+	return i.baseSysProcAttr
+}
+
+// commandSysProcAttr merges the base process attributes with the attributes computed to run a command as a user
+func (i *Instance) commandSysProcAttr(computed *syscall.SysProcAttr) *syscall.SysProcAttr {
+	return mergeSysProcAttr(i.baseSysProcAttr, computed)
+}
+
+// SetVerifyImportNamespace will configure the instance to check that the namespace exists (see Namespaces) before
+// importing source into it (see ImportSource).  Importing into a namespace which does not exist otherwise fails with a
+// session error buried in the output of the import.  The check requires an additional session.
+func (i *Instance) SetVerifyImportNamespace(verify bool) {
+	log.WithField("verify", verify).Debug("Configured import namespace verification")
+	i.verifyImportNS = verify
+}
+
+// VerifyImportNamespace returns whether the namespace is checked before importing source (see SetVerifyImportNamespace)
+func (i *Instance) VerifyImportNamespace() bool {
+	return i.verifyImportNS
+}
+
+// AsUser will configure the instance to execute commands as the provided user for the duration of fn.
+// The previous execution user is always restored when fn returns, even if it panics.
+// This command only functions if the calling program is running as root.
+// It returns any error encountered while switching users or the error returned by fn.
+func (i *Instance) AsUser(execUser string, fn func() error) error {
+	prev := i.executionSysProcAttr
+	defer func() {
+		i.executionSysProcAttr = prev
+	}()
+
+	if err := i.ExecuteAsUser(execUser); err != nil {
+		return err
+	}
+
+	return fn()
+}
+
 func switchUserSysProc(execUser string) (*syscall.SysProcAttr, error) {
 	// no need to switch users if we're already who we want to be
 	if err := checkUser(execUser); err == nil {
 		return &syscall.SysProcAttr{}, nil
 	}
 
+	if !switchUsersSupported {
+		return nil, fmt.Errorf("%w, user: %s", ErrSwitchUserNotSupported, execUser)
+	}
+
 	// if we're not root, we won't be able to switch to someone else
 	if err := checkUser("root"); err != nil {
 		return nil, err
@@ -492,18 +1032,17 @@ func switchUserSysProc(execUser string) (*syscall.SysProcAttr, error) {
 		return nil, err
 	}
 
-	return &syscall.SysProcAttr{
-		Credential: &syscall.Credential{
-			Uid: uint32(uid),
-			Gid: uint32(gid),
-		},
-	}, nil
+	return credentialSysProcAttr(uint32(uid), uint32(gid))
 }
 
 func lookupUser(execUser string) (uid, gid uint64, err error) {
 	var u *user.User
 	u, err = user.Lookup(execUser)
 	if err != nil {
+		var unknown user.UnknownUserError
+		if errors.As(err, &unknown) {
+			err = fmt.Errorf("%w: %w", ErrUserNotFound, err)
+		}
 		return
 	}
 
@@ -525,40 +1064,104 @@ func lookupUser(execUser string) (uid, gid uint64, err error) {
 //	after the ** you must have only a file pattern
 //	To import a single file it would be /a/b/c/file.xml
 //
-// qualifiers are standard Caché import/compile qualifiers, if none are provided a default set will be used
+// qualifiers are standard Caché import/compile qualifiers (see Qualifiers), if none are provided the
+// default import qualifiers (see SetDefaultImportQualifiers) will be used
+// An empty namespace imports into the instance's default namespace (see DefaultNamespace).
 // It returns any output of the import and any error encountered.
 func (i *Instance) ImportSource(namespace, sourcePathGlob string, qualifiers ...string) (string, error) {
-	qstr := strings.TrimSpace(strings.Join(qualifiers, ""))
-	if qstr == "" {
-		qstr = DefaultImportQualifiers
+	result, err := i.ImportSourceResult(namespace, sourcePathGlob, qualifiers...)
+	if result == nil {
+		return "", err
+	}
+
+	return result.Output, err
+}
+
+// ImportSourceWithQualifiers will import the source specified using a glob pattern (see ImportSource) with the
+// provided qualifiers.  If the qualifiers are nil or empty the default import qualifiers will be used.
+// It returns any output of the import and any error encountered.
+func (i *Instance) ImportSourceWithQualifiers(namespace, sourcePathGlob string, qualifiers *Qualifiers) (string, error) {
+	return i.ImportSource(namespace, sourcePathGlob, qualifiers.String())
+}
+
+// ImportSourceDescription will import the source described by the provided ImportDescription into Caché.
+// This allows for control over the import beyond what can be expressed by a glob (e.g. excluding files).
+// If namespace verification is enabled (see SetVerifyImportNamespace), ErrNamespaceNotFound is returned without
+// importing when the namespace does not exist.
+// It returns any output of the import and any error encountered.
+func (i *Instance) ImportSourceDescription(namespace string, id *ImportDescription) (string, error) {
+	namespace, err := i.resolveNamespace(namespace)
+	if err != nil {
+		return "", err
+	}
+
+	if i.verifyImportNS {
+		if err := i.verifyNamespace(namespace); err != nil {
+			return "", err
+		}
 	}
 
-	id, err := NewImportDescription(sourcePathGlob, qstr)
+	return i.importSourceDescription(context.Background(), namespace, id)
+}
+
+// verifyNamespace ensures the namespace is defined in the instance
+func (i *Instance) verifyNamespace(namespace string) error {
+	namespaces, err := i.Namespaces(false)
+	if err != nil {
+		return fmt.Errorf("unable to verify namespace %s: %w", namespace, err)
+	}
+
+	for _, ns := range namespaces {
+		if strings.EqualFold(ns, namespace) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrNamespaceNotFound, namespace)
+}
+
+// importSourceDescription imports the described source (see ImportSourceDescription) into the namespace without
+// verifying it exists.  Executions import their temporary routine with this as listing the namespaces is itself an
+// execution.  The import is killed when ctx is done or the default command timeout elapses.
+func (i *Instance) importSourceDescription(ctx context.Context, namespace string, id *ImportDescription) (string, error) {
+	cmds, err := id.Commands()
 	if err != nil {
 		return "", err
 	}
 
-	cmd := id.String()
 	l := log.WithFields(log.Fields{
 		"instance":   i.Name,
 		"namespace":  namespace,
-		"path":       sourcePathGlob,
-		"qualifiers": qstr,
-		"command":    cmd,
+		"dir":        id.Dir,
+		"pattern":    id.FilePattern,
+		"exclude":    id.Exclude,
+		"qualifiers": id.Qualifiers,
 	})
-	l.Debug("Attempting to import source")
-	o, err := i.SessionCommand(namespace, cmd).CombinedOutput()
-	out := string(o)
-	l.WithField("output", out).Debug("import command result")
-	if err != nil {
-		return out, err
-	}
+	var out strings.Builder
+	for _, cmd := range cmds {
+		l.WithField("command", cmd).Debug("Attempting to import source")
+		o, err := i.runImportCommand(ctx, namespace, cmd)
+		out.WriteString(o)
+		l.WithField("output", o).Debug("import command result")
+		if err != nil {
+			return out.String(), err
+		}
 
-	if !strings.Contains(out, "Load finished successfully.") {
-		return out, ErrLoadFailed
+		if !strings.Contains(o, "Load finished successfully.") {
+			return out.String(), ErrLoadFailed
+		}
 	}
 
-	return out, nil
+	return out.String(), nil
+}
+
+// runImportCommand runs a single import command in the namespace.
+// It returns the combined output of the session and any error encountered.
+func (i *Instance) runImportCommand(ctx context.Context, namespace, cmd string) (string, error) {
+	ctx, cancel := commandContextWithParent(ctx)
+	defer cancel()
+	o, err := i.SessionCommandContext(ctx, namespace, cmd).CombinedOutput()
+	return string(o), commandContextError(ctx, err)
 }
 
 // Execute will read code from the provided io.Reader and execute it in the provided namespace.
@@ -569,8 +1172,15 @@ func (i *Instance) ImportSource(namespace, sourcePathGlob string, qualifiers ...
 //   - You may not have blank lines internal to the code
 //   - You must have a single blank line at the end of the script
 //
+// An empty namespace executes the code in the instance's default namespace (see DefaultNamespace).
+// If a retry policy has been configured (see SetExecuteRetry), executions failing with a known transient session error
+// (e.g. the namespace's database is still mounting) are retried.
 // It returns any output of the execution and any error encountered.
 func (i *Instance) Execute(namespace string, codeReader io.Reader) (string, error) {
+	if i.executeRetry != nil {
+		return i.executeWithRetry(namespace, codeReader)
+	}
+
 	var out bytes.Buffer
 	err := i.ExecuteWithOutput(namespace, codeReader, &out)
 	return out.String(), err
@@ -578,46 +1188,235 @@ func (i *Instance) Execute(namespace string, codeReader io.Reader) (string, erro
 
 // ExecuteWithOutput will read code from the provided io.Reader and execute it in the provided namespace while
 // writing any output to the provided io.Writer.
+// The output is written exactly as the session produced it so callers may decode it themselves if it is not in the
+// expected encoding (see SetSessionIOTranslation).
 func (i *Instance) ExecuteWithOutput(namespace string, codeReader io.Reader, out io.Writer) error {
+	return i.ExecuteWithContext(context.Background(), namespace, codeReader, out)
+}
+
+// ExecuteWithContext will read code from the provided io.Reader and execute it in the provided namespace (see
+// ExecuteWithOutput) while writing any output to the provided io.Writer.
+// The session running the code is killed if the context is done before it exits (e.g. ObjectScript stuck in a loop),
+// the temporary routine is still removed.  The default command timeout (see SetDefaultCommandTimeout) also applies.
+// It returns any error encountered, wrapping the context's error if the session was killed.
+func (i *Instance) ExecuteWithContext(ctx context.Context, namespace string, codeReader io.Reader, out io.Writer) error {
+	_, err := i.executeWithOutput(ctx, namespace, codeReader, executionIO{stdout: out}, ExecuteOptions{})
+	return err
+}
+
+// ExecuteWithIO will read code from the provided io.Reader and execute it in the provided namespace (see
+// ExecuteWithOutput) with the session's principal device connected to the provided stdin, stdout and stderr.
+// This allows driving routines which READ from the principal device, each READ consumes a line of stdin.
+// Any of stdin, stdout or stderr may be nil in which case the session's corresponding stream is not connected.
+// It returns any error encountered.
+func (i *Instance) ExecuteWithIO(namespace string, codeReader io.Reader, stdin io.Reader, stdout, stderr io.Writer) error {
+	_, err := i.executeWithOutput(context.Background(), namespace, codeReader, executionIO{stdin: stdin, stdout: stdout, stderr: stderr}, ExecuteOptions{})
+	return err
+}
+
+// executionIO are the streams connected to the session running the code
+type executionIO struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+// execution describes a completed execution (see executeWithOutput)
+type execution struct {
+	// The output of importing the temporary routine so failures to import can be inspected
+	importOutput string
+	// The name of the temporary routine the code was imported as
+	routineName string
+	// The path to the temporary file, only populated when the file is kept
+	tempFilePath string
+}
+
+// executeWithOutput executes the code as described by ExecuteWithOutput, honoring the provided options.
+func (i *Instance) executeWithOutput(ctx context.Context, namespace string, codeReader io.Reader, stdio executionIO, opts ExecuteOptions) (execution, error) {
+	if err := ctx.Err(); err != nil {
+		return execution{}, err
+	}
+
+	namespace, err := i.resolveNamespace(namespace)
+	if err != nil {
+		return execution{}, err
+	}
+
 	elog := log.WithField("namespace", namespace)
 	elog.Debug("Attempting to execute INT code")
 
+	if err := checkTemporaryDirectoryAccess(i.executionSysProcAttr); err != nil {
+		return execution{}, err
+	}
+
 	codePath, err := i.genExecutorTmpFile(codeReader)
 	if err != nil {
-		return err
+		return execution{}, err
 	}
 	elog.WithField("path", codePath).Debug("Acquired temporary file")
 
-	defer os.Remove(codePath)
+	e := execution{routineName: filepath.Base(codePath)}
+	if opts.KeepTempFile {
+		elog.WithField("path", codePath).Info("Keeping temporary file")
+		e.tempFilePath = codePath
+	} else {
+		defer os.Remove(codePath)
+	}
+
+	id, err := NewImportDescription(codePath, "/compile/keepsource")
+	if err != nil {
+		return execution{tempFilePath: e.tempFilePath}, err
+	}
 
-	if output, err := i.ImportSource(namespace, codePath, "/compile", "/keepsource"); err != nil {
+	if output, err := i.importSourceDescription(ctx, namespace, id); err != nil {
 		elog.WithError(err).WithField("output", output).Error("unable to import")
-		return err
+		return execution{importOutput: output, tempFilePath: e.tempFilePath}, err
 	}
 
-	routineName := filepath.Base(codePath)
-	defer func() {
-		if err := i.removeTempRoutine(namespace, routineName); err != nil {
-			log.WithError(err).Error("Failed to remove temp routine")
-		}
-	}()
+	if opts.KeepRoutine {
+		elog.WithField("routine", e.routineName).Info("Keeping temporary routine")
+	} else {
+		defer func() {
+			if err := i.removeTempRoutine(ctx, namespace, e.routineName); err != nil {
+				log.WithError(err).Error("Failed to remove temp routine")
+			}
+		}()
+	}
 
-	cmd := i.SessionCommand(namespace, "EnsLibMain^"+routineName)
+	ctx, cancel := commandContextWithParent(ctx)
+	defer cancel()
+	cmd := i.SessionCommandContext(ctx, namespace, "EnsLibMain^"+e.routineName)
+
+	cmd.Stdout = stdio.stdout
+	cmd.Stderr = stdio.stderr
+	if stdio.stdin != nil {
+		// the session credentials, if any, must be answered before the code reads its input
+		if cmd.Stdin != nil {
+			cmd.Stdin = io.MultiReader(cmd.Stdin, stdio.stdin)
+		} else {
+			cmd.Stdin = stdio.stdin
+		}
+	}
 
-	cmd.Stdout = out
 	if err := cmd.Start(); err != nil {
 		log.WithError(err).Debug("Failed to start session")
-		return err
+		return e, err
 	}
 
 	elog.Debug("Waiting on session to exit")
-	return cmd.Wait()
+	return e, commandContextError(ctx, cmd.Wait())
+}
+
+// ExecuteInAllNamespaces will read code from the provided io.Reader and execute it in each namespace configured in the
+// instance.  %ALL is never included as it is not a real namespace.  If skipSystem is true, the percent namespaces
+// (e.g. %SYS) and the ISC library namespaces are also skipped.
+// The code is executed in every namespace even if it fails in some of them.
+// It returns the output of the execution keyed by namespace and the errors encountered joined into a single error.
+func (i *Instance) ExecuteInAllNamespaces(codeReader io.Reader, skipSystem bool) (map[string]string, error) {
+	code, err := io.ReadAll(codeReader)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := i.executableNamespaces(skipSystem)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make(map[string]string, len(namespaces))
+	var errs []error
+	for _, namespace := range namespaces {
+		out, err := i.Execute(namespace, bytes.NewReader(code))
+		outputs[namespace] = out
+		if err != nil {
+			errs = append(errs, fmt.Errorf("namespace %s: %w", namespace, err))
+		}
+	}
+
+	return outputs, errors.Join(errs...)
+}
+
+func (i *Instance) executableNamespaces(skipSystem bool) ([]string, error) {
+	mappings, err := i.NamespaceMappings()
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]string, 0, len(mappings))
+	for namespace := range mappings {
+		if namespace == "%ALL" || (skipSystem && isSystemNamespace(namespace)) {
+			continue
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	return namespaces, nil
+}
+
+// DefaultNamespace will determine the namespace used by Execute and ImportSource when no namespace is provided.
+// The product's default application namespace (USER, or ENSDEMO for Ensemble installs without USER) is used if it is
+// configured in the instance's CPF, otherwise %SYS is used.
+// It returns the namespace and any error encountered reading the CPF.
+func (i *Instance) DefaultNamespace() (string, error) {
+	mappings, err := i.NamespaceMappings()
+	if err != nil {
+		return "", err
+	}
+
+	candidates := []string{userNamespace}
+	if i.Product == Ensemble {
+		candidates = append(candidates, ensembleDemoNamespace)
+	}
+
+	for _, candidate := range candidates {
+		if _, ok := mappings[candidate]; ok {
+			return candidate, nil
+		}
+	}
+
+	return systemNamespace, nil
+}
+
+// resolveNamespace returns the provided namespace or the instance's default namespace (see DefaultNamespace) if it is
+// empty
+func (i *Instance) resolveNamespace(namespace string) (string, error) {
+	if namespace != "" {
+		return namespace, nil
+	}
+
+	ns, err := i.DefaultNamespace()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine default namespace: %w", err)
+	}
+
+	log.WithFields(log.Fields{"instance": i.Name, "namespace": ns}).Debug("Using default namespace")
+	return ns, nil
+}
+
+func isSystemNamespace(namespace string) bool {
+	if strings.HasPrefix(namespace, "%") {
+		return true
+	}
+
+	switch strings.ToUpper(namespace) {
+	case "DOCBOOK", "ENSLIB", "HSLIB", "HSSYS":
+		return true
+	default:
+		return false
+	}
 }
 
 // SessionCommand will return a properly configured instance of exec.Cmd to
 // run the provided command (properly formatted for session) in the provided
-// namespace.
+// namespace.  The command outlives this call so it is not bounded by the default
+// command timeout, use SessionCommandContext to bound it.
 func (i *Instance) SessionCommand(namespace, command string) *exec.Cmd {
+	return i.SessionCommandContext(context.Background(), namespace, command)
+}
+
+// SessionCommandContext returns the session command described by SessionCommand which is killed when ctx is done.
+// The caller owns ctx, so a command bounded by a timeout releases it once the command has finished.
+func (i *Instance) SessionCommandContext(ctx context.Context, namespace, command string) *exec.Cmd {
 	args := []string{i.Name}
 	if namespace != "" {
 		args = append(args, "-U", namespace)
@@ -634,14 +1433,50 @@ func (i *Instance) SessionCommand(namespace, command string) *exec.Cmd {
 		args = append(scp[1:], args...)
 	}
 	log.WithFields(log.Fields{"instance": i.Name, "cmd": sc, "args": args}).Debug("session command")
-	cmd := exec.Command(sc, args...)
-	if i.executionSysProcAttr != nil {
-		cmd.SysProcAttr = i.executionSysProcAttr
+	cmd := exec.CommandContext(ctx, sc, args...)
+	cmd.Env = commandEnv()
+	if attr := i.commandSysProcAttr(i.executionSysProcAttr); attr != nil {
+		cmd.SysProcAttr = attr
+	}
+
+	if i.sessionCredentials != nil {
+		cmd.Stdin = strings.NewReader(i.sessionCredentials.username + "\n" + i.sessionCredentials.password + "\n")
 	}
 
 	return cmd
 }
 
+// RunSession will run the provided command (properly formatted for session, see SessionCommand) in the provided
+// namespace.  Unlike Execute the command is passed directly to the session so it can be any routine entry point or
+// class method call the session accepts.
+// A session exiting with a non-zero exit code is not treated as an error, callers should inspect the exit code.
+// It returns the combined output of the session, its exit code and any error encountered running the session itself.
+func (i *Instance) RunSession(namespace, command string) (string, int, error) {
+	ctx, cancel := commandContext()
+	defer cancel()
+	return i.runSessionContext(ctx, namespace, command)
+}
+
+// runSessionContext runs the session described by RunSession, killing it when ctx is done.
+// A session killed because ctx is done is reported as an error wrapping the context's error.
+func (i *Instance) runSessionContext(ctx context.Context, namespace, command string) (string, int, error) {
+	output, err := i.SessionCommandContext(ctx, namespace, command).CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return string(output), -1, commandContextError(ctx, err)
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return string(output), exitErr.ExitCode(), nil
+		}
+
+		return string(output), -1, err
+	}
+
+	return string(output), 0, nil
+}
+
 // ExecuteString will execute the provided code in the specified namespace.
 // code must be properly formatted INT code. See the documentation for Execute for more information.
 // It returns any output of the execution and any error encountered.
@@ -696,6 +1531,20 @@ func (i *Instance) ReadParametersISC() (ParametersISC, error) {
 	return LoadParametersISC(f)
 }
 
+// ParametersAvailable returns whether the instance's parameters ISC file can be read.
+// It can be used to decide whether operations depending on the parameters file (determining the owner, manager, etc.)
+// are worth attempting.
+func (i *Instance) ParametersAvailable() bool {
+	f, err := parameterReader(i.Directory, iscParametersFile)
+	if err != nil {
+		log.WithError(err).WithField("directory", i.Directory).Debug("parameters.isc not available")
+		return false
+	}
+
+	_ = f.Close()
+	return true
+}
+
 func fileParameterReader(directory string, file string) (io.ReadCloser, error) {
 	pfp := filepath.Join(directory, file)
 	f, err := os.Open(pfp)
@@ -712,19 +1561,47 @@ func (i *Instance) WaitForReady(ctx context.Context) error {
 
 // WaitForReadyWithInterval waits for an instance to be up and ready for use or until the interval is exceeded
 func (i *Instance) WaitForReadyWithInterval(ctx context.Context, interval time.Duration) error {
+	return i.WaitForStatus(ctx, interval, InstanceStatus.Ready)
+}
+
+// WaitForStatus waits for the instance's status to satisfy the provided predicate (e.g. InstanceStatus.Up or a check
+// for a specific mirror transition state clearing), updating the instance (see Update) every interval.
+// Failures to update the instance are ignored.
+// It returns nil once the predicate is satisfied or the context's error if the context is done first.
+func (i *Instance) WaitForStatus(ctx context.Context, interval time.Duration, pred func(InstanceStatus) bool) error {
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-time.After(interval):
 			_ = i.Update()
-			if i.Status.Ready() {
+			if pred(i.Status) {
 				return nil
 			}
 		}
 	}
 }
 
+// LastUsed returns the time a down instance was last used, as reported in its activity.
+// It returns an error if the instance's activity does not contain a last used time.
+func (i *Instance) LastUsed() (time.Time, error) {
+	return i.activityTime(lastUsedActivityPrefix)
+}
+
+// RunningSince returns the time a running instance was started, as reported in its activity.
+// It returns an error if the instance's activity does not contain a start time.
+func (i *Instance) RunningSince() (time.Time, error) {
+	return i.activityTime(sinceActivityPrefix)
+}
+
+func (i *Instance) activityTime(prefix string) (time.Time, error) {
+	if !strings.HasPrefix(i.Activity, prefix) {
+		return time.Time{}, fmt.Errorf("activity does not contain a %q time, activity: %s", strings.TrimSpace(prefix), i.Activity)
+	}
+
+	return time.ParseInLocation(time.ANSIC, strings.TrimSpace(strings.TrimPrefix(i.Activity, prefix)), time.Local)
+}
+
 func qlistStatus(statusAndTime string) (InstanceStatus, string) {
 	s := strings.SplitN(statusAndTime, ",", 2)
 	var a string
@@ -735,7 +1612,7 @@ func qlistStatus(statusAndTime string) (InstanceStatus, string) {
 }
 
 func (i *Instance) genExecutorTmpFile(codeReader io.Reader) (path string, error error) {
-	tmpFile, err := os.CreateTemp(executeTemporaryDirectory, "ELEXEC")
+	tmpFile, err := createExecutorTmpFile()
 	if err != nil {
 		return "", err
 	}
@@ -747,7 +1624,11 @@ func (i *Instance) genExecutorTmpFile(codeReader io.Reader) (path string, error
 	}
 
 	routineName := filepath.Base(tmpFile.Name())
-	if _, err := tmpFile.Write([]byte(fmt.Sprintf(importXMLHeader, routineName))); err != nil {
+	var prologue string
+	if i.ioTranslation != "" {
+		prologue = fmt.Sprintf(ioTranslationStatement, i.ioTranslation)
+	}
+	if _, err := tmpFile.Write([]byte(fmt.Sprintf(importXMLHeader, routineName, prologue))); err != nil {
 		return "", fmt.Errorf("failed to write XML header: %w", err)
 	}
 
@@ -760,12 +1641,8 @@ func (i *Instance) genExecutorTmpFile(codeReader io.Reader) (path string, error
 	}
 
 	// Need to set the permissions here or the file will be owned by root and the execution will fail
-	if i.executionSysProcAttr != nil && i.executionSysProcAttr.Credential != nil {
-		if err := os.Chown(
-			tmpFile.Name(),
-			int(i.executionSysProcAttr.Credential.Uid),
-			int(i.executionSysProcAttr.Credential.Gid),
-		); err != nil {
+	if uid, gid, ok := sysProcCredential(i.executionSysProcAttr); ok {
+		if err := os.Chown(tmpFile.Name(), int(uid), int(gid)); err != nil {
 			return "", fmt.Errorf("failed to set ownership on import file: %w", err)
 		}
 	}
@@ -773,33 +1650,61 @@ func (i *Instance) genExecutorTmpFile(codeReader io.Reader) (path string, error
 	return tmpFile.Name(), nil
 }
 
+// createExecutorTmpFile creates a new temporary file whose name is the execute temporary prefix followed by random hex
+// digits.  The name is also the name of the temporary routine so it must not collide with the routines of concurrent
+// executions in the same namespace, including those started from other hosts with their own temporary directories.
+func createExecutorTmpFile() (*os.File, error) {
+	for attempt := 0; ; attempt++ {
+		name := filepath.Join(temporaryDirectory(), fmt.Sprintf("%s%016X", executeTempPrefix, rand.Uint64()))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) && attempt < maxTmpFileAttempts {
+			continue
+		}
+
+		return f, err
+	}
+}
+
+// SessionBinary returns the session command this instance uses, honoring SessionPath and the product specific defaults.
+// For IRIS this includes the session subcommand (e.g. "iris session"), for Caché/Ensemble it is the csession path.
+// Environment variables in the configured value are expanded.
+func (i *Instance) SessionBinary() string {
+	return i.sessionCommand()
+}
+
 func (i *Instance) sessionCommand() string {
 	if i.SessionPath == "" {
 		switch i.Product {
 		case Iris:
-			return globalIrisSessionCommand
+			return os.ExpandEnv(globalIrisSessionCommand)
 		default:
-			return globalCSessionPath
+			return os.ExpandEnv(globalCSessionPath)
 		}
 	}
 
-	return i.SessionPath
+	return os.ExpandEnv(i.SessionPath)
+}
+
+// ControlBinary returns the path to the control executable this instance uses (e.g. ccontrol or iris), honoring
+// ControlPath and the product specific defaults.  Environment variables in the configured value are expanded.
+func (i *Instance) ControlBinary() string {
+	return i.controlPath()
 }
 
 func (i *Instance) controlPath() string {
 	if i.ControlPath == "" {
 		switch i.Product {
 		case Iris:
-			return globalIrisPath
+			return os.ExpandEnv(globalIrisPath)
 		default:
-			return globalCControlPath
+			return os.ExpandEnv(globalCControlPath)
 		}
 	}
 
-	return i.ControlPath
+	return os.ExpandEnv(i.ControlPath)
 }
 
-func (i *Instance) getUserAndGroupFromParameters(desc, userKey, groupKey string) (string, string, error) {
+func (i *Instance) getUserAndGroupFromParameters(userKey, groupKey string, errUser, errGroup error) (string, string, error) {
 	pi, err := i.ReadParametersISC()
 	if err != nil {
 		return "", "", err
@@ -807,18 +1712,20 @@ func (i *Instance) getUserAndGroupFromParameters(desc, userKey, groupKey string)
 
 	owner := pi.Value(userKey)
 	if owner == "" {
-		return "", "", fmt.Errorf("%s user not found in parameters file", desc)
+		return "", "", errUser
 	}
 
 	group := pi.Value(groupKey)
 	if group == "" {
-		return "", "", fmt.Errorf("%s group not found in parameters file", desc)
+		return "", "", errGroup
 	}
 
 	return owner, group, nil
 }
 
-func (i *Instance) removeTempRoutine(namespace, path string) error {
+// removeTempRoutine removes the temporary routine from the namespace.  The routine is removed even when ctx is done
+// (e.g. the execution was canceled), only the default command timeout limits the removal.
+func (i *Instance) removeTempRoutine(ctx context.Context, namespace, path string) error {
 	routineName := filepath.Base(path)
 	l := log.WithFields(log.Fields{
 		"instance":  i.Name,
@@ -827,7 +1734,9 @@ func (i *Instance) removeTempRoutine(namespace, path string) error {
 	})
 
 	l.Debug("Removing temporary routine")
-	cmd := i.SessionCommand(namespace, fmt.Sprintf(`##class(%%Routine).Delete("%s",0,1)`, routineName))
+	ctx, cancel := commandContextWithParent(context.WithoutCancel(ctx))
+	defer cancel()
+	cmd := i.SessionCommandContext(ctx, namespace, fmt.Sprintf(`##class(%%Routine).Delete("%s",0,1)`, routineName))
 	if err := cmd.Start(); err != nil {
 		l.WithError(err).Error("Failed to start deletion")
 		return fmt.Errorf("failed to start routine deletion: %w", err)
@@ -835,21 +1744,22 @@ func (i *Instance) removeTempRoutine(namespace, path string) error {
 
 	if err := cmd.Wait(); err != nil {
 		l.WithError(err).Error("Failed to delete routine")
-		return fmt.Errorf("failed to execute routine deletion: %w", err)
+		return fmt.Errorf("failed to execute routine deletion: %w", commandContextError(ctx, err))
 	}
 
 	return nil
 }
 
-func (i *Instance) determineProduct(product string) Product {
-	if product != "" {
-		return ParseProduct(product)
-	}
-
-	pi, err := i.ReadParametersISC()
-	if err != nil {
-		return Cache
+// determineProduct determines the product and edition of the instance from the product reported by qlist, falling
+// back to the product in the instance's parameters ISC file when qlist does not report it
+func (i *Instance) determineProduct(product string) (Product, Edition) {
+	if product == "" {
+		pi, err := i.ReadParametersISC()
+		if err != nil {
+			return Cache, EditionNone
+		}
+		product = pi.Value("product_info.name")
 	}
 
-	return ParseProduct(pi.Value("product_info.name"))
+	return ParseProduct(product), ParseEdition(product)
 }
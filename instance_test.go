@@ -19,10 +19,12 @@ package isclib
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/user"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -195,6 +197,82 @@ var _ = Describe("Instance", func() {
 		})
 	})
 
+	Describe("UpdateFromQListLenient", func() {
+		const shortqlist = "LEGACY^/ensemble/instances/legacy/^2008.1^running, since Fri May 13 22:07:02 2016^cache.cpf"
+		BeforeEach(func() {
+			instance = new(Instance)
+		})
+		It("Populates the pieces which are present", func() {
+			warnings, err := instance.UpdateFromQListLenient(shortqlist)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("insufficient pieces"), ContainSubstring("SuperServer"), ContainSubstring("WebServer"), ContainSubstring("JDBC")))
+			Expect(instance.Name).To(Equal("LEGACY"))
+			Expect(instance.Directory).To(Equal("/ensemble/instances/legacy/"))
+			Expect(instance.Version).To(Equal("2008.1"))
+			Expect(instance.Status).To(Equal(InstanceStatusRunning))
+			Expect(instance.CPFFileName).To(Equal("cache.cpf"))
+			Expect(instance.SuperServerPort).To(BeZero())
+		})
+		It("Leaves invalid ports zero-valued", func() {
+			warnings, err := instance.UpdateFromQListLenient("INSTTEST^/ensemble/instances/insttest/^2015.2^down^cache.cpf^56772^^62972")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("WebServer")))
+			Expect(instance.SuperServerPort).To(Equal(56772))
+			Expect(instance.WebServerPort).To(BeZero())
+			Expect(instance.JDBCPort).To(Equal(62972))
+		})
+		It("Returns an error without an instance name", func() {
+			_, err := instance.UpdateFromQListLenient("")
+			Expect(err).To(HaveOccurred())
+		})
+		Context("Lenient qlist parsing is enabled", func() {
+			BeforeEach(func() {
+				SetLenientQList(true)
+			})
+			AfterEach(func() {
+				SetLenientQList(false)
+			})
+			It("Loads instances from a short qlist", func() {
+				instance, err := InstanceFromQList(shortqlist)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(instance.Name).To(Equal("LEGACY"))
+			})
+		})
+	})
+
+	Describe("LastUsed and RunningSince", func() {
+		Context("The instance is down", func() {
+			BeforeEach(func() {
+				instance, _ = InstanceFromQList(legacyqlist)
+			})
+			It("Returns the last used time", func() {
+				Expect(instance.LastUsed()).To(Equal(time.Date(2016, time.September, 15, 18, 58, 30, 0, time.Local)))
+			})
+			It("Does not return a running since time", func() {
+				_, err := instance.RunningSince()
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("The instance is running", func() {
+			BeforeEach(func() {
+				instance, _ = InstanceFromQList(cacheqlist)
+			})
+			It("Returns the running since time", func() {
+				Expect(instance.RunningSince()).To(Equal(time.Date(2016, time.May, 13, 22, 7, 2, 0, time.Local)))
+			})
+			It("Does not return a last used time", func() {
+				_, err := instance.LastUsed()
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("The day of the month is a single digit", func() {
+			It("Parses the time", func() {
+				instance = &Instance{Activity: "last used Mon Jul  3 08:00:01 2023"}
+				Expect(instance.LastUsed()).To(Equal(time.Date(2023, time.July, 3, 8, 0, 1, 0, time.Local)))
+			})
+		})
+	})
+
 	Describe("DetermineISCDatFileName", func() {
 		Context("The product is Cache", func() {
 			It("Returns the correct DAT filename", func() {
@@ -274,6 +352,39 @@ var _ = Describe("Instance", func() {
 					Expect(cmd.SysProcAttr.Credential.Uid).To(Equal(uint32(0)))
 					Expect(cmd.SysProcAttr.Credential.Gid).To(Equal(uint32(0)))
 				})
+				It("Merges the credential with the base process attributes", func() {
+					base := &syscall.SysProcAttr{Setsid: true, Credential: &syscall.Credential{Uid: 1000, Gid: 1000}}
+					instance.SetBaseSysProcAttr(base)
+					cmd := instance.SessionCommand("TEST", "TEST^TEST")
+					Expect(cmd.SysProcAttr.Setsid).To(BeTrue())
+					Expect(cmd.SysProcAttr.Credential).To(Equal(&syscall.Credential{Uid: 0, Gid: 0}))
+					Expect(base.Credential.Uid).To(Equal(uint32(1000)), "the base attributes are not modified")
+				})
+			})
+			Context("with base process attributes configured", func() {
+				It("Starts the command with the base process attributes", func() {
+					instance.SetBaseSysProcAttr(&syscall.SysProcAttr{Setsid: true})
+					cmd := instance.SessionCommand("TEST", "TEST^TEST")
+					Expect(cmd.SysProcAttr.Setsid).To(BeTrue())
+					Expect(cmd.SysProcAttr.Credential).To(BeNil())
+				})
+			})
+		})
+		Describe("With session credentials", func() {
+			BeforeEach(func() {
+				instance, _ = InstanceFromQList(cacheqlist)
+				instance.SetSessionCredentials("_SYSTEM", "SYS")
+			})
+			It("Provides the credentials on stdin rather than the command line", func() {
+				cmd := instance.SessionCommand("TEST", "TEST^TEST")
+				Expect(cmd.Args).To(BeEquivalentTo([]string{"/somepath/csession", "INSTTEST", "-U", "TEST", "TEST^TEST"}))
+				Expect(cmd.Stdin).NotTo(BeNil())
+				Expect(io.ReadAll(cmd.Stdin)).To(BeEquivalentTo("_SYSTEM\nSYS\n"))
+			})
+			It("Does not provide credentials once cleared", func() {
+				instance.ClearSessionCredentials()
+				cmd := instance.SessionCommand("TEST", "TEST^TEST")
+				Expect(cmd.Stdin).To(BeNil())
 			})
 		})
 		Describe("The product is Ensemble", func() {
@@ -403,6 +514,465 @@ var _ = Describe("Instance", func() {
 			})
 		})
 	})
+	Describe("CPFFilePath", func() {
+		It("Returns the CPF in the installation directory", func() {
+			instance, _ = InstanceFromQList(cacheqlist)
+			Expect(instance.CPFFilePath()).To(Equal("/ensemble/instances/insttest/cache.cpf"))
+		})
+		It("Returns the CPF in the data directory with durable %SYS", func() {
+			instance, _ = InstanceFromQList(irisqlist)
+			Expect(instance.CPFFilePath()).To(Equal("/mgr/config/iris.cpf"))
+		})
+	})
+	Describe("NamespaceGlobalsDat", func() {
+		BeforeEach(func() {
+			instance = &Instance{Name: instanceName, DataDirectory: GinkgoT().TempDir(), CPFFileName: "cache.cpf"}
+			cpf := "[Databases]\nCACHESYS=/db/cachesys/\nUSER=/db/user/\nROUTINES=/db/routines/\n\n[Namespaces]\n%SYS=CACHESYS\nUSER=USER\nAPP=USER,ROUTINES\nORPHAN=MISSING\n\n"
+			Expect(os.WriteFile(instance.CPFFilePath(), []byte(cpf), 0644)).To(Succeed())
+		})
+		It("Parses the namespace mappings", func() {
+			mappings, err := instance.NamespaceMappings()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappings).To(HaveLen(4))
+			Expect(mappings["USER"]).To(Equal(NamespaceMapping{Namespace: "USER", GlobalsDatabase: "USER", RoutinesDatabase: "USER"}))
+			Expect(mappings["APP"]).To(Equal(NamespaceMapping{Namespace: "APP", GlobalsDatabase: "USER", RoutinesDatabase: "ROUTINES"}))
+		})
+		It("Returns the globals database of the namespace", func() {
+			dat, err := instance.NamespaceGlobalsDat("app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dat.Path).To(Equal("/db/user/"))
+			Expect(dat.Exists).To(BeFalse())
+		})
+		It("Returns an error for an unknown namespace", func() {
+			_, err := instance.NamespaceGlobalsDat("NOPE")
+			Expect(err).To(MatchError(ErrNamespaceNotFound))
+		})
+		It("Returns an error for an unknown database", func() {
+			_, err := instance.NamespaceGlobalsDat("ORPHAN")
+			Expect(err).To(MatchError(ErrDatabaseNotFound))
+		})
+	})
+	Describe("ExecuteInAllNamespaces", func() {
+		BeforeEach(func() {
+			origCSessionCommand = CSessionPath()
+			SetCSessionPath(filepath.Join(GinkgoT().TempDir(), "missing-csession"))
+			instance = &Instance{Name: instanceName, DataDirectory: GinkgoT().TempDir(), CPFFileName: "cache.cpf"}
+			cpf := "[Namespaces]\n%ALL=%DEFAULTDB\n%SYS=CACHESYS\nDOCBOOK=DOCBOOK\nUSER=USER\nAPP=APP\n\n"
+			Expect(os.WriteFile(instance.CPFFilePath(), []byte(cpf), 0644)).To(Succeed())
+		})
+		AfterEach(func() {
+			SetCSessionPath(origCSessionCommand)
+		})
+		It("Determines the namespaces to execute in", func() {
+			Expect(instance.executableNamespaces(false)).To(Equal([]string{"%SYS", "APP", "DOCBOOK", "USER"}))
+			Expect(instance.executableNamespaces(true)).To(Equal([]string{"APP", "USER"}))
+		})
+		It("Attempts every namespace and aggregates the errors", func() {
+			SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+			defer SetExecuteTemporaryDirectory("")
+			outputs, err := instance.ExecuteInAllNamespaces(bytes.NewBufferString("MAIN\n quit\n"), true)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("namespace APP"))
+			Expect(err.Error()).To(ContainSubstring("namespace USER"))
+			Expect(outputs).To(HaveKey("APP"))
+			Expect(outputs).To(HaveKey("USER"))
+		})
+	})
+	Describe("CPFFiles", func() {
+		BeforeEach(func() {
+			instance = &Instance{Name: instanceName, DataDirectory: GinkgoT().TempDir(), CPFFileName: "cache.cpf"}
+		})
+		It("Returns the CPF files in the data directory", func() {
+			for _, f := range []string{"cache.cpf", "maint.cpf", "cache.cpf_20240101", "cache.ids"} {
+				Expect(os.WriteFile(filepath.Join(instance.DataDirectory, f), []byte{}, 0644)).To(Succeed())
+			}
+			Expect(os.Mkdir(filepath.Join(instance.DataDirectory, "dir.cpf"), 0755)).To(Succeed())
+			Expect(instance.CPFFiles()).To(Equal([]string{"cache.cpf", "maint.cpf"}))
+		})
+		It("Returns an error when the data directory does not exist", func() {
+			instance.DataDirectory = filepath.Join(instance.DataDirectory, "missing")
+			_, err := instance.CPFFiles()
+			Expect(err).To(MatchError(os.ErrNotExist))
+		})
+	})
+	Describe("Stat", func() {
+		var script string
+		BeforeEach(func() {
+			script = filepath.Join(GinkgoT().TempDir(), "control")
+			Expect(os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\"\n"), 0755)).To(Succeed())
+			instance = &Instance{Name: instanceName, ControlPath: script}
+		})
+		It("Runs the stat subcommand for the instance", func() {
+			Expect(instance.Stat()).To(Equal("stat INSTTEST\n"))
+		})
+		It("Passes additional options", func() {
+			Expect(instance.Stat("-e1", "-m1")).To(Equal("stat INSTTEST -e1 -m1\n"))
+		})
+		It("Returns an error when the command fails", func() {
+			Expect(os.WriteFile(script, []byte("#!/bin/sh\necho failed\nexit 1\n"), 0755)).To(Succeed())
+			out, err := instance.Stat()
+			Expect(err).To(HaveOccurred())
+			Expect(out).To(Equal("failed\n"))
+		})
+	})
+	Describe("Restart", func() {
+		var (
+			dir       string
+			statePath string
+		)
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			statePath = filepath.Join(dir, "state")
+			Expect(os.WriteFile(statePath, []byte("running"), 0644)).To(Succeed())
+			// the fake control command records each subcommand and changes the state reported by qlist
+			control := filepath.Join(dir, "control")
+			script := "#!/bin/sh\necho \"$@\" >> " + filepath.Join(dir, "calls") + "\n" +
+				"case \"$1\" in\n  stop) echo down > " + statePath + " ;;\n  start) echo running > " + statePath + " ;;\nesac\n"
+			Expect(os.WriteFile(control, []byte(script), 0755)).To(Succeed())
+			getQlist = func(string, *syscall.SysProcAttr) (string, error) {
+				state, err := os.ReadFile(statePath)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("INSTTEST^%s^2018.1.1.643.0^%s, since Fri May 13 22:07:02 2016^iris.cpf^56772^57772^62972^ok^IRIS", dir, bytes.TrimSpace(state)), nil
+			}
+			instance = &Instance{Name: instanceName, ControlPath: control}
+			Expect(instance.Update()).To(Succeed())
+		})
+		AfterEach(func() {
+			getQlist = qlist
+		})
+		It("Stops and then starts the instance", func() {
+			Expect(instance.Restart()).To(Succeed())
+			Expect(os.ReadFile(filepath.Join(dir, "calls"))).To(BeEquivalentTo("stop INSTTEST quietly\nstart INSTTEST quietly\n"))
+			Expect(instance.Status.Ready()).To(BeTrue())
+		})
+		It("Reports a failure to stop", func() {
+			Expect(os.WriteFile(instance.ControlPath, []byte("#!/bin/sh\nexit 1\n"), 0755)).To(Succeed())
+			err := instance.Restart()
+			Expect(err).To(MatchError(ErrRestartStop))
+			Expect(err).NotTo(MatchError(ErrRestartStart))
+		})
+		It("Reports a failure to start", func() {
+			Expect(os.WriteFile(instance.ControlPath, []byte("#!/bin/sh\n[ \"$1\" = stop ] && echo down > "+statePath+"\nexit 0\n"), 0755)).To(Succeed())
+			err := instance.Restart()
+			Expect(err).To(MatchError(ErrRestartStart))
+			Expect(err).NotTo(MatchError(ErrRestartStop))
+		})
+	})
+	Describe("StartContext and StopContext", func() {
+		var ctx context.Context
+		BeforeEach(func() {
+			dir := GinkgoT().TempDir()
+			control := filepath.Join(dir, "control")
+			Expect(os.WriteFile(control, []byte("#!/bin/sh\nexec sleep 10\n"), 0755)).To(Succeed())
+			instance = &Instance{Name: instanceName, ControlPath: control}
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+			DeferCleanup(cancel)
+		})
+		It("Kills a hung start when the context is done", func() {
+			instance.Status = InstanceStatusDown
+			Expect(instance.StartContext(ctx)).To(MatchError(context.DeadlineExceeded))
+		})
+		It("Kills a hung stop when the context is done", func() {
+			instance.Status = InstanceStatusRunning
+			Expect(instance.StopContext(ctx)).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+	Describe("IsHealthy", func() {
+		var (
+			dir     string
+			origDir string
+			status  string
+		)
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			origDir = procDirectory
+			procDirectory = filepath.Join(dir, "proc")
+			Expect(os.MkdirAll(filepath.Join(procDirectory, "1"), 0755)).To(Succeed())
+			Expect(os.Symlink("/usr/bin/init", filepath.Join(procDirectory, "1", "exe"))).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(procDirectory, "self"), 0755)).To(Succeed())
+
+			status = "running, since Fri May 13 22:07:02 2016"
+			getQlist = func(string, *syscall.SysProcAttr) (string, error) {
+				return fmt.Sprintf("INSTTEST^%s^2018.1.1.643.0^%s^iris.cpf^56772^57772^62972^ok^IRIS", dir, status), nil
+			}
+
+			session := filepath.Join(dir, "session")
+			Expect(os.WriteFile(session, []byte("#!/bin/sh\nexit 0\n"), 0755)).To(Succeed())
+			instance = &Instance{Name: instanceName, SessionPath: session}
+		})
+		AfterEach(func() {
+			procDirectory = origDir
+			getQlist = qlist
+		})
+		addInstanceProcess := func() {
+			Expect(os.MkdirAll(filepath.Join(procDirectory, "42"), 0755)).To(Succeed())
+			Expect(os.Symlink(filepath.Join(dir, "bin", "irisdb"), filepath.Join(procDirectory, "42", "exe"))).To(Succeed())
+		}
+		It("Is healthy when running with processes and responding", func() {
+			addInstanceProcess()
+			Expect(instance.IsHealthy()).To(BeTrue())
+		})
+		It("Is not healthy when the instance is down", func() {
+			status = "down, last used Fri May 13 22:07:02 2016"
+			addInstanceProcess()
+			Expect(instance.IsHealthy()).To(BeFalse())
+		})
+		It("Is not healthy when none of the instance's processes exist", func() {
+			Expect(instance.IsHealthy()).To(BeFalse())
+		})
+		It("Is not healthy when the session does not respond", func() {
+			addInstanceProcess()
+			Expect(os.WriteFile(instance.SessionPath, []byte("#!/bin/sh\necho '<PROTECT>'\nexit 1\n"), 0755)).To(Succeed())
+			Expect(instance.IsHealthy()).To(BeFalse())
+		})
+		It("Is not healthy when the session does not respond in time", func() {
+			addInstanceProcess()
+			DeferCleanup(func(timeout time.Duration) { healthPingTimeout = timeout }, healthPingTimeout)
+			healthPingTimeout = 50 * time.Millisecond
+			Expect(os.WriteFile(instance.SessionPath, []byte("#!/bin/sh\nexec sleep 10\n"), 0755)).To(Succeed())
+			Expect(instance.IsHealthy()).To(BeFalse())
+		})
+		It("Relies on the session when the processes cannot be inspected", func() {
+			Expect(os.RemoveAll(procDirectory)).To(Succeed())
+			Expect(instance.IsHealthy()).To(BeTrue())
+		})
+	})
+	Describe("RunSession", func() {
+		var script string
+		BeforeEach(func() {
+			script = filepath.Join(GinkgoT().TempDir(), "session")
+			instance = &Instance{Name: instanceName, SessionPath: script}
+		})
+		It("Returns the output and a zero exit code", func() {
+			Expect(os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\"\n"), 0755)).To(Succeed())
+			out, code, err := instance.RunSession("USER", "^%SS")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(code).To(Equal(0))
+			Expect(out).To(Equal("INSTTEST -U USER ^%SS\n"))
+		})
+		It("Returns a non-zero exit code without an error", func() {
+			Expect(os.WriteFile(script, []byte("#!/bin/sh\necho '<UNDEFINED>'\nexit 3\n"), 0755)).To(Succeed())
+			out, code, err := instance.RunSession("USER", "^%SS")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(code).To(Equal(3))
+			Expect(out).To(Equal("<UNDEFINED>\n"))
+		})
+		It("Returns an error when the session cannot be run", func() {
+			_, code, err := instance.RunSession("USER", "^%SS")
+			Expect(err).To(HaveOccurred())
+			Expect(code).To(Equal(-1))
+		})
+	})
+	Describe("RunControlCommand", func() {
+		BeforeEach(func() {
+			script := filepath.Join(GinkgoT().TempDir(), "control")
+			Expect(os.WriteFile(script, []byte("#!/bin/sh\nfor a in \"$@\"; do echo \"[$a]\"; done\n"), 0755)).To(Succeed())
+			instance = &Instance{Name: instanceName, ControlPath: script}
+		})
+		It("Provides the instance name after the subcommand", func() {
+			Expect(instance.RunControlCommand("list")).To(Equal("[list]\n[INSTTEST]\n"))
+		})
+		It("Passes the arguments without interpreting them", func() {
+			Expect(instance.RunControlCommand("stop", "quietly; rm -rf /", "$HOME")).To(Equal("[stop]\n[INSTTEST]\n[quietly; rm -rf /]\n[$HOME]\n"))
+		})
+		It("Requires a subcommand", func() {
+			_, err := instance.RunControlCommand()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("StartWithCPF", func() {
+		BeforeEach(func() {
+			instance = &Instance{Name: instanceName, DataDirectory: GinkgoT().TempDir(), CPFFileName: "cache.cpf"}
+		})
+		It("Requires a CPF", func() {
+			Expect(instance.StartWithCPF("")).To(MatchError("a CPF must be provided"))
+		})
+		It("Returns an error when the CPF does not exist", func() {
+			Expect(instance.StartWithCPF("maint.cpf")).To(MatchError(os.ErrNotExist))
+		})
+	})
+	Describe("StartupSettings", func() {
+		var settings StartupSettings
+		BeforeEach(func() {
+			instance = &Instance{Name: instanceName, DataDirectory: GinkgoT().TempDir(), CPFFileName: "cache.cpf"}
+		})
+		Context("The CPF contains a shutdown timeout", func() {
+			BeforeEach(func() {
+				cpf := "[config]\nShutdownTimeout=1\n\n[Startup]\nDefaultPort=1972\nShutdownTimeout=120\n\n[Journal]\nFileSizeLimit=1024\n"
+				Expect(os.WriteFile(instance.CPFFilePath(), []byte(cpf), 0644)).To(Succeed())
+				settings, err = instance.StartupSettings()
+			})
+			It("Does not return an error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("Returns the configured value", func() {
+				Expect(settings.ShutdownTimeout).To(Equal(120 * time.Second))
+			})
+		})
+		Context("The CPF does not contain a shutdown timeout", func() {
+			BeforeEach(func() {
+				Expect(os.WriteFile(instance.CPFFilePath(), []byte("[Startup]\nDefaultPort=1972\n"), 0644)).To(Succeed())
+				settings, err = instance.StartupSettings()
+			})
+			It("Returns the default value", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(settings.ShutdownTimeout).To(Equal(300 * time.Second))
+			})
+		})
+		Context("The CPF contains an invalid shutdown timeout", func() {
+			BeforeEach(func() {
+				Expect(os.WriteFile(instance.CPFFilePath(), []byte("[Startup]\nShutdownTimeout=abc\n"), 0644)).To(Succeed())
+				settings, err = instance.StartupSettings()
+			})
+			It("Returns an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("The CPF does not exist", func() {
+			It("Returns an error", func() {
+				_, err = instance.StartupSettings()
+				Expect(err).To(MatchError(os.ErrNotExist))
+			})
+		})
+	})
+	Describe("JournalSettings", func() {
+		BeforeEach(func() {
+			instance = &Instance{Name: instanceName, DataDirectory: GinkgoT().TempDir(), CPFFileName: "iris.cpf"}
+		})
+		It("Returns the configured values", func() {
+			cpf := "[Journal]\nAlternateDirectory=/journal2/\nBackupsBeforePurge=0\nCompressFiles=0\nCurrentDirectory=/journal1/\nDaysBeforePurge=7\nFileSizeLimit=512\nFreezeOnError=1\nJournalFilePrefix=APP\n"
+			Expect(os.WriteFile(instance.CPFFilePath(), []byte(cpf), 0644)).To(Succeed())
+			Expect(instance.JournalSettings()).To(Equal(JournalSettings{
+				FileSizeLimit:      512 * 1024 * 1024,
+				DaysBeforePurge:    7,
+				BackupsBeforePurge: 0,
+				FreezeOnError:      true,
+				CompressFiles:      false,
+				FilePrefix:         "APP",
+			}))
+		})
+		It("Returns the default values for missing settings", func() {
+			Expect(os.WriteFile(instance.CPFFilePath(), []byte("[Journal]\nCurrentDirectory=/journal1/\n"), 0644)).To(Succeed())
+			Expect(instance.JournalSettings()).To(Equal(JournalSettings{
+				FileSizeLimit:      1024 * 1024 * 1024,
+				DaysBeforePurge:    2,
+				BackupsBeforePurge: 2,
+				CompressFiles:      true,
+			}))
+		})
+		It("Returns an error for an invalid setting", func() {
+			Expect(os.WriteFile(instance.CPFFilePath(), []byte("[Journal]\nFileSizeLimit=big\n"), 0644)).To(Succeed())
+			_, err := instance.JournalSettings()
+			Expect(err).To(MatchError(ContainSubstring("FileSizeLimit")))
+		})
+	})
+	Describe("Directories", func() {
+		Context("Without durable %SYS", func() {
+			It("Returns directories relative to the installation directory", func() {
+				instance, _ = InstanceFromQList(cacheqlist)
+				Expect(instance.BinDirectory()).To(Equal("/ensemble/instances/insttest/bin"))
+				Expect(instance.LibDirectory()).To(Equal("/ensemble/instances/insttest/lib"))
+				Expect(instance.CSPDirectory()).To(Equal("/ensemble/instances/insttest/csp"))
+				Expect(instance.MgrDirectory()).To(Equal("/ensemble/instances/insttest/mgr"))
+			})
+		})
+		Context("With durable %SYS", func() {
+			It("Returns the mgr directory relative to the data directory", func() {
+				instance, _ = InstanceFromQList(irisqlist)
+				Expect(instance.BinDirectory()).To(Equal("/ensemble/instances/insttest/bin"))
+				Expect(instance.LibDirectory()).To(Equal("/ensemble/instances/insttest/lib"))
+				Expect(instance.CSPDirectory()).To(Equal("/ensemble/instances/insttest/csp"))
+				Expect(instance.MgrDirectory()).To(Equal("/mgr/config/mgr"))
+			})
+		})
+	})
+	Describe("AsUser", func() {
+		var (
+			prev        *syscall.SysProcAttr
+			currentUser *user.User
+		)
+		BeforeEach(func() {
+			currentUser, err = user.Current()
+			Expect(err).NotTo(HaveOccurred())
+			prev = &syscall.SysProcAttr{Setpgid: true}
+			instance = &Instance{Name: instanceName, executionSysProcAttr: prev}
+		})
+		It("Runs the function as the provided user", func() {
+			var during *syscall.SysProcAttr
+			Expect(instance.AsUser(currentUser.Username, func() error {
+				during = instance.executionSysProcAttr
+				return nil
+			})).To(Succeed())
+			Expect(during).NotTo(BeIdenticalTo(prev))
+		})
+		It("Returns the error from the function and restores the previous user", func() {
+			fnErr := fmt.Errorf("failed")
+			Expect(instance.AsUser(currentUser.Username, func() error { return fnErr })).To(MatchError(fnErr))
+			Expect(instance.executionSysProcAttr).To(BeIdenticalTo(prev))
+		})
+		It("Restores the previous user when the function panics", func() {
+			Expect(func() {
+				_ = instance.AsUser(currentUser.Username, func() error { panic("boom") })
+			}).To(PanicWith("boom"))
+			Expect(instance.executionSysProcAttr).To(BeIdenticalTo(prev))
+		})
+		It("Does not run the function when the user cannot be switched to", func() {
+			called := false
+			err := instance.AsUser("no-such-user-for-isclib", func() error {
+				called = true
+				return nil
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(called).To(BeFalse())
+			Expect(instance.executionSysProcAttr).To(BeIdenticalTo(prev))
+		})
+	})
+	Describe("ConsoleLogPath", func() {
+		var mgrDir string
+		BeforeEach(func() {
+			dir := GinkgoT().TempDir()
+			mgrDir = filepath.Join(dir, "mgr")
+			Expect(os.MkdirAll(mgrDir, 0755)).To(Succeed())
+			instance = &Instance{Name: instanceName, DataDirectory: dir}
+		})
+		Context("The product is Cache", func() {
+			BeforeEach(func() {
+				instance.Product = Cache
+			})
+			It("Returns cconsole.log when it exists", func() {
+				Expect(os.WriteFile(filepath.Join(mgrDir, "cconsole.log"), []byte{}, 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(mgrDir, "messages.log"), []byte{}, 0644)).To(Succeed())
+				Expect(instance.ConsoleLogPath()).To(Equal(filepath.Join(mgrDir, "cconsole.log")))
+			})
+			It("Falls back to messages.log", func() {
+				Expect(os.WriteFile(filepath.Join(mgrDir, "messages.log"), []byte{}, 0644)).To(Succeed())
+				Expect(instance.ConsoleLogPath()).To(Equal(filepath.Join(mgrDir, "messages.log")))
+			})
+		})
+		Context("The product is Iris", func() {
+			BeforeEach(func() {
+				instance.Product = Iris
+			})
+			It("Returns messages.log when it exists", func() {
+				Expect(os.WriteFile(filepath.Join(mgrDir, "cconsole.log"), []byte{}, 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(mgrDir, "messages.log"), []byte{}, 0644)).To(Succeed())
+				Expect(instance.ConsoleLogPath()).To(Equal(filepath.Join(mgrDir, "messages.log")))
+			})
+			It("Falls back to cconsole.log", func() {
+				Expect(os.WriteFile(filepath.Join(mgrDir, "cconsole.log"), []byte{}, 0644)).To(Succeed())
+				Expect(instance.ConsoleLogPath()).To(Equal(filepath.Join(mgrDir, "cconsole.log")))
+			})
+		})
+		Context("No console log exists", func() {
+			It("Returns a not exist error", func() {
+				_, err := instance.ConsoleLogPath()
+				Expect(err).To(MatchError(os.ErrNotExist))
+			})
+		})
+	})
 	Describe("WaitForReady", func() {
 		Context("With timeout", func() {
 			Context("Does not come up", func() {
@@ -444,6 +1014,36 @@ var _ = Describe("Instance", func() {
 			})
 		})
 	})
+	Describe("WaitForStatus", func() {
+		var statuses []string
+		BeforeEach(func() {
+			statuses = []string{"sign-on inhibited:primary transition", "sign-on inhibited:primary transition", "running"}
+			getQlist = func(string, *syscall.SysProcAttr) (string, error) {
+				status := statuses[0]
+				if len(statuses) > 1 {
+					statuses = statuses[1:]
+				}
+				return fmt.Sprintf("INSTTEST^/ensemble/instances/insttest/^2018.1.1.643.0^%s, since Fri May 13 22:07:02 2016^iris.cpf^56772^57772^62972^ok^IRIS", status), nil
+			}
+			instance = &Instance{Name: instanceName}
+		})
+		AfterEach(func() {
+			getQlist = qlist
+		})
+		It("Returns once the predicate is satisfied", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			Expect(instance.WaitForStatus(ctx, time.Millisecond, func(s InstanceStatus) bool {
+				return s != InstanceStatusPrimaryTransition
+			})).To(Succeed())
+			Expect(instance.Status).To(Equal(InstanceStatusRunning))
+		})
+		It("Returns the context error when the predicate is never satisfied", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			Expect(instance.WaitForStatus(ctx, time.Millisecond, InstanceStatus.Down)).To(MatchError(context.DeadlineExceeded))
+		})
+	})
 	Describe("sessionCommand", func() {
 		Describe("The product is Cache", func() {
 			BeforeEach(func() {
@@ -461,6 +1061,9 @@ var _ = Describe("Instance", func() {
 				It("returns the default session command", func() {
 					Expect(instance.sessionCommand()).To(Equal(globalCSessionPath))
 				})
+				It("exposes the session command publicly", func() {
+					Expect(instance.SessionBinary()).To(Equal(globalCSessionPath))
+				})
 			})
 		})
 		Describe("The product is Ensemble", func() {
@@ -497,9 +1100,44 @@ var _ = Describe("Instance", func() {
 				It("returns the default session command", func() {
 					Expect(instance.sessionCommand()).To(Equal(globalIrisSessionCommand))
 				})
+				It("exposes the session command publicly", func() {
+					Expect(instance.SessionBinary()).To(Equal(globalIrisSessionCommand))
+				})
 			})
 		})
 	})
+	Describe("environment variables in paths", func() {
+		BeforeEach(func() {
+			GinkgoT().Setenv("ISCLIB_TEST_INSTALL_DIR", "/opt/isc")
+			origCSessionCommand = CSessionPath()
+			origIrisSessionCommand = IrisSessionCommand()
+			SetCSessionPath("$ISCLIB_TEST_INSTALL_DIR/bin/csession")
+			SetIrisSessionCommand("${ISCLIB_TEST_INSTALL_DIR}/bin/iris session")
+		})
+		AfterEach(func() {
+			SetCSessionPath(origCSessionCommand)
+			SetIrisSessionCommand(origIrisSessionCommand)
+		})
+		It("Keeps the configured value", func() {
+			Expect(CSessionPath()).To(Equal("$ISCLIB_TEST_INSTALL_DIR/bin/csession"))
+		})
+		It("Expands the global paths when building commands", func() {
+			instance, _ = InstanceFromQList(cacheqlist)
+			Expect(instance.SessionCommand("", "").Path).To(Equal("/opt/isc/bin/csession"))
+			instance, _ = InstanceFromQList(irisqlist)
+			Expect(instance.SessionCommand("", "").Args).To(BeEquivalentTo([]string{"/opt/isc/bin/iris", "session", "INSTTEST"}))
+		})
+		It("Expands the instance paths", func() {
+			instance, _ = InstanceFromQList(cacheqlist)
+			instance.ControlPath = "$ISCLIB_TEST_INSTALL_DIR/bin/ccontrol"
+			Expect(instance.controlPath()).To(Equal("/opt/isc/bin/ccontrol"))
+		})
+		It("Expands the temporary directory", func() {
+			SetExecuteTemporaryDirectory("$ISCLIB_TEST_INSTALL_DIR/tmp")
+			defer SetExecuteTemporaryDirectory("")
+			Expect(temporaryDirectory()).To(Equal("/opt/isc/tmp"))
+		})
+	})
 	Describe("controlPath", func() {
 		Describe("The product is Cache", func() {
 			BeforeEach(func() {
@@ -517,6 +1155,9 @@ var _ = Describe("Instance", func() {
 				It("returns the default control command", func() {
 					Expect(instance.controlPath()).To(Equal(globalCControlPath))
 				})
+				It("exposes the control command publicly", func() {
+					Expect(instance.ControlBinary()).To(Equal(globalCControlPath))
+				})
 			})
 		})
 		Describe("The product is Ensemble", func() {
@@ -553,6 +1194,93 @@ var _ = Describe("Instance", func() {
 				It("returns the default session command", func() {
 					Expect(instance.controlPath()).To(Equal(globalIrisPath))
 				})
+				It("exposes the control command publicly", func() {
+					Expect(instance.ControlBinary()).To(Equal(globalIrisPath))
+				})
+			})
+		})
+	})
+
+	Describe("DetermineManager and DetermineOwner", func() {
+		setParameters := func(content string) {
+			parameterReader = func(directory string, file string) (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewBufferString(content)), nil
+			}
+		}
+		BeforeEach(func() {
+			instance = &Instance{Name: instanceName, Directory: "/ensemble/instances/insttest/", Product: Cache}
+		})
+		It("Returns the configured users and groups", func() {
+			setParameters("security_settings.manager_user: mgr\nsecurity_settings.manager_group: mgrgrp\nsecurity_settings.cache_user: own\nsecurity_settings.cache_group: owngrp\n")
+			u, g, err := instance.DetermineManager()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u).To(Equal("mgr"))
+			Expect(g).To(Equal("mgrgrp"))
+			u, g, err = instance.DetermineOwner()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u).To(Equal("own"))
+			Expect(g).To(Equal("owngrp"))
+		})
+		It("Returns distinguishable errors for missing users", func() {
+			setParameters("security_settings.manager_group: mgrgrp\nsecurity_settings.cache_group: owngrp\n")
+			_, _, err := instance.DetermineManager()
+			Expect(err).To(MatchError(ErrManagerUserNotConfigured))
+			_, _, err = instance.DetermineOwner()
+			Expect(err).To(MatchError(ErrOwnerUserNotConfigured))
+		})
+		It("Returns distinguishable errors for missing groups", func() {
+			setParameters("security_settings.manager_user: mgr\nsecurity_settings.iris_user: own\n")
+			instance.Product = Iris
+			_, _, err := instance.DetermineManager()
+			Expect(err).To(MatchError(ErrManagerGroupNotConfigured))
+			_, _, err = instance.DetermineOwner()
+			Expect(err).To(MatchError(ErrOwnerGroupNotConfigured))
+		})
+		It("Returns a parameters error when the file cannot be read", func() {
+			parameterReader = func(directory string, file string) (io.ReadCloser, error) {
+				return nil, os.ErrPermission
+			}
+			_, _, err := instance.DetermineManager()
+			var pIscErr *ParametersISCError
+			Expect(errors.As(err, &pIscErr)).To(BeTrue())
+			Expect(err).To(MatchError(os.ErrPermission))
+		})
+		It("Returns a user not found error when the manager does not exist", func() {
+			if os.Getuid() != 0 {
+				Skip("switching users requires root")
+			}
+			setParameters("security_settings.manager_user: no-such-user-for-isclib\nsecurity_settings.manager_group: mgrgrp\n")
+			Expect(instance.ExecuteAsManager()).To(MatchError(ErrUserNotFound))
+		})
+	})
+
+	Describe("ParametersAvailable", func() {
+		BeforeEach(func() {
+			instance = &Instance{Name: instanceName, Directory: "/ensemble/instances/insttest/"}
+		})
+		Context("parameters.isc can be read", func() {
+			It("Returns true", func() {
+				Expect(instance.ParametersAvailable()).To(BeTrue())
+			})
+		})
+		Context("parameters.isc does not exist", func() {
+			BeforeEach(func() {
+				parameterReader = func(directory string, file string) (io.ReadCloser, error) {
+					return nil, os.ErrNotExist
+				}
+			})
+			It("Returns false", func() {
+				Expect(instance.ParametersAvailable()).To(BeFalse())
+			})
+		})
+		Context("parameters.isc cannot be read", func() {
+			BeforeEach(func() {
+				parameterReader = func(directory string, file string) (io.ReadCloser, error) {
+					return nil, os.ErrPermission
+				}
+			})
+			It("Returns false", func() {
+				Expect(instance.ParametersAvailable()).To(BeFalse())
 			})
 		})
 	})
@@ -598,4 +1326,51 @@ var _ = Describe("Instance", func() {
 			})
 		})
 	})
+	Describe("DefaultNamespace", func() {
+		var dir string
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			instance = &Instance{Name: instanceName, DataDirectory: dir, CPFFileName: "cache.cpf"}
+		})
+		writeNamespaces := func(namespaces ...string) {
+			cpf := "[Namespaces]\n%SYS=CACHESYS\n"
+			for _, ns := range namespaces {
+				cpf += ns + "=" + ns + "\n"
+			}
+			Expect(os.WriteFile(filepath.Join(dir, "cache.cpf"), []byte(cpf), 0644)).To(Succeed())
+		}
+		DescribeTable("Resolving the default namespace",
+			func(product Product, namespaces []string, expected string) {
+				instance.Product = product
+				writeNamespaces(namespaces...)
+				Expect(instance.DefaultNamespace()).To(Equal(expected))
+			},
+			Entry("USER is configured", Cache, []string{"USER"}, "USER"),
+			Entry("USER is preferred for Ensemble", Ensemble, []string{"ENSDEMO", "USER"}, "USER"),
+			Entry("Ensemble without USER", Ensemble, []string{"ENSDEMO"}, "ENSDEMO"),
+			Entry("ENSDEMO is not used for IRIS", Iris, []string{"ENSDEMO"}, "%SYS"),
+			Entry("No application namespace", Cache, []string{}, "%SYS"),
+		)
+		It("Returns an error when the CPF cannot be read", func() {
+			_, err := instance.DefaultNamespace()
+			Expect(err).To(MatchError(os.ErrNotExist))
+		})
+		It("Is used when executing without a namespace", func() {
+			writeNamespaces("USER")
+			calls := filepath.Join(dir, "calls")
+			session := filepath.Join(dir, "session")
+			Expect(os.WriteFile(session, []byte("#!/bin/sh\necho \"$@\" >> "+calls+"\necho 'Load finished successfully.'\n"), 0755)).To(Succeed())
+			instance.SessionPath = session
+			SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+			DeferCleanup(SetExecuteTemporaryDirectory, "")
+
+			_, err := instance.ExecuteString("", "MAIN\n quit\n\n")
+			Expect(err).NotTo(HaveOccurred())
+			b, err := os.ReadFile(calls)
+			Expect(err).NotTo(HaveOccurred())
+			for _, call := range bytes.Split(bytes.TrimSpace(b), []byte("\n")) {
+				Expect(string(call)).To(HavePrefix(instanceName + " -U USER "))
+			}
+		})
+	})
 })
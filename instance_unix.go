@@ -0,0 +1,81 @@
+//go:build !windows
+
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"os"
+	"syscall"
+)
+
+// switchUsersSupported is whether commands can be run as another user (see ExecuteAsUser)
+const switchUsersSupported = true
+
+// credentialSysProcAttr returns the process attributes which run a command as the provided user and group
+func credentialSysProcAttr(uid, gid uint32) (*syscall.SysProcAttr, error) {
+	return &syscall.SysProcAttr{
+		Credential: &syscall.Credential{
+			Uid: uid,
+			Gid: gid,
+		},
+	}, nil
+}
+
+// mergeSysProcAttr returns a copy of the base process attributes using the credential of the computed attributes, if
+// they switch users
+func mergeSysProcAttr(base, computed *syscall.SysProcAttr) *syscall.SysProcAttr {
+	if base == nil {
+		return computed
+	}
+
+	merged := *base
+	if computed != nil && computed.Credential != nil {
+		merged.Credential = computed.Credential
+	}
+
+	return &merged
+}
+
+// sysProcCredential returns the user and group the process attributes run a command as, if they switch users
+func sysProcCredential(attr *syscall.SysProcAttr) (uid, gid uint32, ok bool) {
+	if attr == nil || attr.Credential == nil {
+		return 0, 0, false
+	}
+
+	return attr.Credential.Uid, attr.Credential.Gid, true
+}
+
+// fileOwnership returns the user and group owning the file, if the file system reports them
+func fileOwnership(fi os.FileInfo) (uid, gid uint32, ok bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return st.Uid, st.Gid, true
+}
+
+// fileIdentity returns the device and inode identifying the file, if the file system reports them
+func fileIdentity(fi os.FileInfo) (dev, ino uint64, ok bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return uint64(st.Dev), st.Ino, true
+}
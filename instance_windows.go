@@ -0,0 +1,58 @@
+//go:build windows
+
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// switchUsersSupported is whether commands can be run as another user (see ExecuteAsUser)
+const switchUsersSupported = false
+
+// credentialSysProcAttr returns ErrSwitchUserNotSupported as Windows processes cannot be started as another user by uid
+func credentialSysProcAttr(uid, gid uint32) (*syscall.SysProcAttr, error) {
+	return nil, fmt.Errorf("%w, uid: %d, gid: %d", ErrSwitchUserNotSupported, uid, gid)
+}
+
+// mergeSysProcAttr returns a copy of the base process attributes, the computed attributes never switch users on Windows
+func mergeSysProcAttr(base, computed *syscall.SysProcAttr) *syscall.SysProcAttr {
+	if base == nil {
+		return computed
+	}
+
+	merged := *base
+	return &merged
+}
+
+// sysProcCredential never reports a user as the process attributes cannot switch users on Windows
+func sysProcCredential(*syscall.SysProcAttr) (uid, gid uint32, ok bool) {
+	return 0, 0, false
+}
+
+// fileOwnership never reports an owner as Windows files are not owned by a uid and gid
+func fileOwnership(os.FileInfo) (uid, gid uint32, ok bool) {
+	return 0, 0, false
+}
+
+// fileIdentity never reports an identity as the file information does not include one on Windows
+func fileIdentity(os.FileInfo) (dev, ino uint64, ok bool) {
+	return 0, 0, false
+}
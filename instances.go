@@ -16,6 +16,15 @@ limitations under the License.
 
 package isclib
 
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+)
+
 // Instances represents a collection of Caché/Ensemble instances
 type Instances []*Instance
 
@@ -30,3 +39,79 @@ func (instances Instances) Update() error {
 
 	return nil
 }
+
+// manageable returns the instances the current user is able to manage, those it is the manager or owner of.
+// All instances are manageable by root.  Instances whose parameters cannot be read or do not name their manager and
+// owner are not manageable, they are skipped so one misconfigured instance does not hide the others.
+func (instances Instances) manageable() (Instances, error) {
+	root, err := isRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	if root {
+		return instances, nil
+	}
+
+	manageable := make(Instances, 0, len(instances))
+	for _, instance := range instances {
+		ok, err := instance.canManage()
+		if err != nil {
+			log.WithField("instance", instance.Name).WithError(err).Warn("Unable to determine whether the instance can be managed, skipping it")
+			continue
+		}
+
+		if ok {
+			manageable = append(manageable, instance)
+		}
+	}
+
+	return manageable, nil
+}
+
+// canManage reports whether the current user is the manager or owner of the instance
+func (i *Instance) canManage() (bool, error) {
+	if ok, err := i.IsManager(); err != nil || ok {
+		return ok, err
+	}
+
+	return i.IsOwner()
+}
+
+// WriteTable will write the instances to the provided writer as a table with aligned columns
+// (name, status, version, product and ports) and a header row, suitable for display in a terminal.
+// It returns any error encountered.
+func (instances Instances) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "NAME\tSTATUS\tVERSION\tPRODUCT\tSUPERSERVER\tWEBSERVER"); err != nil {
+		return err
+	}
+
+	for _, instance := range instances {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\n",
+			instance.Name,
+			instance.Status,
+			instance.Version,
+			instance.Product.String(),
+			instance.SuperServerPort,
+			instance.WebServerPort,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+// WriteJSON will write the instances to the provided writer as an indented JSON array using the Instance JSON
+// representation.  No instances are written as an empty array.
+// It returns any error encountered.
+func (instances Instances) WriteJSON(w io.Writer) error {
+	if instances == nil {
+		instances = Instances{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(instances)
+}
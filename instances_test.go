@@ -0,0 +1,65 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("Instances", func() {
+	var instances Instances
+
+	BeforeEach(func() {
+		instances = Instances{
+			{Name: "DOCKER", Status: InstanceStatusRunning, Version: "2018.1.1.643.0", Product: Iris, SuperServerPort: 1972, WebServerPort: 57772},
+			{Name: "LEGACYINSTANCE", Status: InstanceStatusDown, Version: "2015.2.2.805.0.16216", Product: Cache, SuperServerPort: 56772, WebServerPort: 57773},
+		}
+	})
+
+	Describe("WriteTable", func() {
+		It("Writes aligned columns with a header", func() {
+			var b bytes.Buffer
+			Expect(instances.WriteTable(&b)).To(Succeed())
+			Expect(b.String()).To(Equal(
+				"NAME            STATUS   VERSION               PRODUCT  SUPERSERVER  WEBSERVER\n" +
+					"DOCKER          running  2018.1.1.643.0        IRIS     1972         57772\n" +
+					"LEGACYINSTANCE  down     2015.2.2.805.0.16216  Cache    56772        57773\n"))
+		})
+	})
+
+	Describe("WriteJSON", func() {
+		It("Writes the instances as a JSON array", func() {
+			var b bytes.Buffer
+			Expect(instances.WriteJSON(&b)).To(Succeed())
+			var decoded []map[string]interface{}
+			Expect(json.Unmarshal(b.Bytes(), &decoded)).To(Succeed())
+			Expect(decoded).To(HaveLen(2))
+			Expect(decoded[0]).To(HaveKeyWithValue("name", "DOCKER"))
+			Expect(decoded[1]).To(HaveKeyWithValue("superServerPort", BeEquivalentTo(56772)))
+		})
+		It("Writes an empty array when there are no instances", func() {
+			var b bytes.Buffer
+			Expect(Instances(nil).WriteJSON(&b)).To(Succeed())
+			Expect(b.String()).To(Equal("[]\n"))
+		})
+	})
+})
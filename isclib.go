@@ -83,8 +83,15 @@ package isclib
 
 import (
 	"bufio"
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 )
 
 const (
@@ -92,6 +99,15 @@ const (
 	defaultIrisPath     = "iris"
 	defaultCSessionPath = "csession"
 	iscParametersFile   = "parameters.isc"
+	// ISC limits instance names to 255 characters
+	maxInstanceNameLength = 255
+	// DefaultExecuteTempPrefix is the default prefix for the temporary files (and routines) used for ObjectScript execution
+	DefaultExecuteTempPrefix = "ELEXEC"
+	// DefaultQListDelimiter is the default delimiter between the fields of qlist output
+	DefaultQListDelimiter = "^"
+	// The environment variables which relocate the ISC registry for IRIS and Caché respectively
+	irisRegistryEnv  = "IRISSYS"
+	cacheRegistryEnv = "CACHESYS"
 )
 
 const (
@@ -99,7 +115,7 @@ const (
 <Export generator="Cache" version="25">
 <Routine name="%s" type="MAC" languagemode="0"><![CDATA[
 EnsLibMain() public {
-	try {
+	try {%s
 		do MAIN
 	} catch ex {
 		do BACK^%%ETN
@@ -110,6 +126,9 @@ EnsLibMain() public {
 }
 
 `
+	// Switches the I/O translation of the principal device before running the code
+	ioTranslationStatement = `
+		do ##class(%%SYS.NLS.Device).SetIO("%s")`
 	importXMLFooter = `
 ]]></Routine>
 </Export>`
@@ -121,12 +140,31 @@ var (
 	globalCSessionPath        = defaultCSessionPath
 	globalIrisSessionCommand  = fmt.Sprintf("%s session", defaultIrisPath)
 	executeTemporaryDirectory = "" // Default is system temp directory
+	registryPath              = "" // Default is the registry location of the control commands
+	defaultCommandTimeout     time.Duration
+	lenientQList              bool
+	qlistDelimiter            = DefaultQListDelimiter
+	executeTempPrefix         = DefaultExecuteTempPrefix
+	defaultImportQualifiers   = DefaultImportQualifiers
+	routinePrefixRegexp       = regexp.MustCompile(`^%?[A-Za-z][A-Za-z0-9]*$`)
+	ioTranslationRegexp       = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9\-]*$`)
+	instanceNameRegexp        = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_\-]*$`)
+
+	// ErrInvalidTempPrefix is an error signifying that a temporary file prefix would not produce a legal routine name
+	ErrInvalidTempPrefix = errors.New("the temporary file prefix must be a legal routine name")
+	// ErrInvalidInstanceName is an error signifying that an instance name does not follow the ISC rules
+	ErrInvalidInstanceName = errors.New("invalid instance name")
+	// ErrInvalidIOTranslation is an error signifying that an I/O translation table name is not valid
+	ErrInvalidIOTranslation = errors.New("invalid I/O translation table name")
+	// ErrInvalidQListDelimiter is an error signifying that a qlist field delimiter is not valid
+	ErrInvalidQListDelimiter = errors.New("the qlist delimiter must not be empty")
 )
 
 // CControlPath returns the current path to the ccontrol executable
 func CControlPath() string { return globalCControlPath }
 
-// SetCControlPath sets the current path to the ccontrol executable
+// SetCControlPath sets the current path to the ccontrol executable.
+// Environment variables (e.g. $INSTALL_DIR/bin/ccontrol) are expanded each time the executable is run rather than when set.
 func SetCControlPath(path string) {
 	globalCControlPath = path
 }
@@ -134,7 +172,8 @@ func SetCControlPath(path string) {
 // IrisPath returns the current path to the iris executable
 func IrisPath() string { return globalIrisPath }
 
-// SetIrisPath sets the current path to the iris executable
+// SetIrisPath sets the current path to the iris executable.
+// Environment variables (e.g. $INSTALL_DIR/bin/iris) are expanded each time the executable is run rather than when set.
 func SetIrisPath(path string) {
 	globalIrisPath = path
 }
@@ -142,7 +181,8 @@ func SetIrisPath(path string) {
 // CSessionPath returns the current path to the csession executable
 func CSessionPath() string { return globalCSessionPath }
 
-// SetCSessionPath sets the current path to the csession executable
+// SetCSessionPath sets the current path to the csession executable.
+// Environment variables (e.g. $INSTALL_DIR/bin/csession) are expanded each time the executable is run rather than when set.
 func SetCSessionPath(path string) {
 	globalCSessionPath = path
 }
@@ -150,11 +190,25 @@ func SetCSessionPath(path string) {
 // IrisSessionCommand returns the current string for the iris session command
 func IrisSessionCommand() string { return globalIrisSessionCommand }
 
-// SetIrisSessionCommand sets the current string for the iris session command
+// SetIrisSessionCommand sets the current string for the iris session command.
+// Environment variables (e.g. $INSTALL_DIR/bin/iris session) are expanded each time the command is run rather than when set.
 func SetIrisSessionCommand(path string) {
 	globalIrisSessionCommand = path
 }
 
+// RegistryPath returns the directory of the ISC registry used by the ISC commands (see SetRegistryPath).
+// "" means the control commands use their default registry location.
+func RegistryPath() string { return registryPath }
+
+// SetRegistryPath sets the directory of the ISC registry (e.g. /home/irisowner/irissys) used by the ISC commands run by
+// isclib (qlist, start, stop, control commands and sessions).  The registry lists the installed instances so instances
+// registered in a relocated registry can only be discovered and controlled when it is set.
+// Passing "" will result in the control commands using their default registry location (or $IRISSYS/$CACHESYS).
+// Environment variables are expanded each time a command is run rather than when set.
+func SetRegistryPath(path string) {
+	registryPath = path
+}
+
 // ExecuteTemporaryDirectory returns the directory where temporary files for ObjectScript execution will be placed.
 // "" means the system default temp directory.
 func ExecuteTemporaryDirectory() string {
@@ -163,26 +217,189 @@ func ExecuteTemporaryDirectory() string {
 
 // SetExecuteTemporaryDirectory sets the directory where temporary files for ObjectScript execution will be placed.
 // Passing "" will result in using the system default temp directory.
+// Environment variables (e.g. $TMPDIR/isclib) are expanded each time the directory is used rather than when set.
 func SetExecuteTemporaryDirectory(path string) {
 	executeTemporaryDirectory = path
 }
 
-// LoadInstances returns a listing of all Caché/Ensemble instances on this system.
+// LenientQList returns whether qlist output is parsed leniently (see SetLenientQList)
+func LenientQList() bool { return lenientQList }
+
+// SetLenientQList sets whether qlist output is parsed leniently when loading and updating instances.
+// When lenient, instances are loaded from qlist output missing some of the standard pieces (e.g. from hosts with very
+// old versions) with the missing values left zero-valued and a warning logged instead of failing
+// (see Instance.UpdateFromQListLenient).
+func SetLenientQList(lenient bool) {
+	lenientQList = lenient
+}
+
+// QListDelimiter returns the current delimiter between the fields of qlist output (see SetQListDelimiter)
+func QListDelimiter() string { return qlistDelimiter }
+
+// SetQListDelimiter sets the delimiter between the fields of qlist output used when loading and updating instances.
+// Every known version of qlist uses the default (see DefaultQListDelimiter) so changing it is rarely needed, it exists
+// to adapt the parser to other qlist formats (e.g. output which has been transformed before being parsed).
+// It returns an error if the delimiter is empty.
+func SetQListDelimiter(delimiter string) error {
+	if delimiter == "" {
+		return ErrInvalidQListDelimiter
+	}
+
+	qlistDelimiter = delimiter
+	return nil
+}
+
+// DefaultCommandTimeout returns the current default timeout for ISC commands (see SetDefaultCommandTimeout)
+func DefaultCommandTimeout() time.Duration { return defaultCommandTimeout }
+
+// SetDefaultCommandTimeout sets the time the ISC commands run by isclib (qlist, start, stop, control commands, source
+// imports, code execution and sessions) are allowed to run before they are killed.  This protects callers from commands which
+// never return (e.g. a hung shutdown or ObjectScript stuck in a loop).
+// A timeout of 0 (the default) allows commands to run indefinitely.
+func SetDefaultCommandTimeout(d time.Duration) {
+	defaultCommandTimeout = d
+}
+
+// commandContext returns a context for running an ISC command which is done once the default command timeout elapses
+func commandContext() (context.Context, context.CancelFunc) {
+	return commandContextWithParent(context.Background())
+}
+
+// commandContextWithParent returns a context for running an ISC command which is done once the parent is done or the
+// default command timeout elapses, whichever comes first
+func commandContextWithParent(parent context.Context) (context.Context, context.CancelFunc) {
+	if defaultCommandTimeout <= 0 {
+		return context.WithCancel(parent)
+	}
+
+	return context.WithTimeout(parent, defaultCommandTimeout)
+}
+
+// commandContextError wraps the error of a command with the context's error if the command was killed because the
+// context was done
+func commandContextError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ctx.Err(), err)
+	}
+
+	return err
+}
+
+// commandEnv returns the environment for running an ISC command.
+// It returns nil (the current process's environment) unless a registry path is set (see SetRegistryPath).
+func commandEnv() []string {
+	path := os.ExpandEnv(registryPath)
+	if path == "" {
+		return nil
+	}
+
+	return append(os.Environ(), irisRegistryEnv+"="+path, cacheRegistryEnv+"="+path)
+}
+
+// temporaryDirectory returns the directory where temporary files for ObjectScript execution will be placed with any
+// environment variables expanded
+func temporaryDirectory() string {
+	if dir := os.ExpandEnv(executeTemporaryDirectory); dir != "" {
+		return dir
+	}
+
+	return os.TempDir()
+}
+
+// GetDefaultImportQualifiers returns the qualifiers used when importing source without any qualifiers.
+// The initial value is DefaultImportQualifiers.
+func GetDefaultImportQualifiers() string {
+	return defaultImportQualifiers
+}
+
+// SetDefaultImportQualifiers sets the qualifiers used when importing source without any qualifiers.
+// Passing "" will restore DefaultImportQualifiers.
+func SetDefaultImportQualifiers(qualifiers string) {
+	qualifiers = strings.TrimSpace(qualifiers)
+	if qualifiers == "" {
+		qualifiers = DefaultImportQualifiers
+	}
+
+	defaultImportQualifiers = qualifiers
+}
+
+// ExecuteTempPrefix returns the prefix used for the temporary files created for ObjectScript execution.
+// The name of the temporary file (the prefix followed by 16 random hex digits) is also used as the name of the
+// temporary routine.
+func ExecuteTempPrefix() string {
+	return executeTempPrefix
+}
+
+// SetExecuteTempPrefix sets the prefix used for the temporary files created for ObjectScript execution.
+// The prefix must be a legal routine name as the name of the temporary file is also used as the name of the temporary routine.
+// It returns ErrInvalidTempPrefix if the prefix is not a legal routine name.
+func SetExecuteTempPrefix(prefix string) error {
+	if !routinePrefixRegexp.MatchString(prefix) {
+		return fmt.Errorf("%w: %q", ErrInvalidTempPrefix, prefix)
+	}
+
+	executeTempPrefix = prefix
+	return nil
+}
+
+// LoadInstances returns a listing of all Caché/Ensemble/IRIS instances on this system.
+// When both the iris and ccontrol commands are available, the instances listed by each are merged with any instance
+// listed by both (the same name and directory) only included once.
 // It returns the list of instances and any error encountered.
 func LoadInstances() (Instances, error) {
-	qs, err := qlist("", nil)
+	qs, err := qlists()
 	if err != nil {
 		return nil, err
 	}
 
 	instances := make(Instances, 0)
-	scanner := bufio.NewScanner(bytes.NewBufferString(qs))
-	for scanner.Scan() {
-		instance, err := InstanceFromQList(scanner.Text())
+	seen := make(map[string]bool)
+	for _, q := range qs {
+		listed, err := InstancesFromQListReader(strings.NewReader(q))
 		if err != nil {
 			return nil, err
 		}
 
+		for _, instance := range listed {
+			key := strings.ToUpper(instance.Name) + "^" + filepath.Clean(instance.Directory)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			// if the status is unknown, we may be running as a different user,
+			// do the full update (running qlist again as the correct user)
+			if instance.Status == InstanceStatusUnknown {
+				if err := instance.Update(); err != nil {
+					return nil, err
+				}
+			}
+
+			instances = append(instances, instance)
+		}
+	}
+
+	return instances, nil
+}
+
+// InstancesFromQListReader parses the output of an argumentless qlist (one instance per line) read from the provided
+// reader, such as output captured from a remote host.  Blank lines are ignored.
+// Unlike InstanceFromQList, the instances are never updated by running qlist locally.
+// It returns the instances in the order they are listed and any error encountered.
+func InstancesFromQListReader(r io.Reader) (Instances, error) {
+	instances := make(Instances, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		instance := new(Instance)
+		if err := instance.UpdateFromQList(line); err != nil {
+			return nil, err
+		}
+
 		instances = append(instances, instance)
 	}
 
@@ -193,10 +410,26 @@ func LoadInstances() (Instances, error) {
 	return instances, nil
 }
 
+// LoadManageableInstances returns a listing of the instances on the system which the current user is able to manage
+// (the instances it is the manager or owner of, see IsManager and IsOwner).  All instances are returned for root.
+// It returns the instances and any error encountered.
+func LoadManageableInstances() (Instances, error) {
+	instances, err := LoadInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	return instances.manageable()
+}
+
 // LoadInstance retrieves a single instance by name.
 // The instance name is case-insensitive.
-// It returns the instance and any error encountered.
+// It returns the instance and any error encountered, including ErrInstanceNotFound if the instance is not installed.
 func LoadInstance(name string) (*Instance, error) {
+	if err := ValidInstanceName(name); err != nil {
+		return nil, err
+	}
+
 	i := &Instance{Name: name}
 	if err := i.Update(); err != nil {
 		return nil, err
@@ -205,6 +438,36 @@ func LoadInstance(name string) (*Instance, error) {
 	return i, nil
 }
 
+// InstanceExists determines whether an instance with the provided name is installed on this system.
+// It returns whether the instance exists and any error encountered determining it (e.g. qlist failing).
+func InstanceExists(name string) (bool, error) {
+	if _, err := LoadInstance(name); err != nil {
+		if errors.Is(err, ErrInstanceNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ValidInstanceName checks that the provided name follows the ISC rules for instance names.
+// Instance names must start with a letter, may only contain letters, digits, underscores and hyphens and may be at most
+// 255 characters long.  Names are case-insensitive (ISC stores them in upper case) so either case is accepted.
+// It returns an error wrapping ErrInvalidInstanceName describing the problem or nil if the name is valid.
+func ValidInstanceName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: name is empty", ErrInvalidInstanceName)
+	case len(name) > maxInstanceNameLength:
+		return fmt.Errorf("%w: name is longer than %d characters", ErrInvalidInstanceName, maxInstanceNameLength)
+	case !instanceNameRegexp.MatchString(name):
+		return fmt.Errorf("%w: %q must start with a letter and contain only letters, digits, underscores and hyphens", ErrInvalidInstanceName, name)
+	}
+
+	return nil
+}
+
 // InstanceFromQList will parse the output of a qlist into an Instance struct.
 // It expects the results of a qlist for a single instance as a string.
 // It returns the parsed instance and any error encountered.
@@ -0,0 +1,313 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("ValidInstanceName", func() {
+	DescribeTable("Validating names",
+		func(name string, valid bool) {
+			if valid {
+				Expect(ValidInstanceName(name)).To(Succeed())
+			} else {
+				Expect(ValidInstanceName(name)).To(MatchError(ErrInvalidInstanceName))
+			}
+		},
+		Entry("upper case", "IRIS", true),
+		Entry("lower case", "iris", true),
+		Entry("digits, underscores and hyphens", "IRIS_2024-1", true),
+		Entry("maximum length", strings.Repeat("A", 255), true),
+		Entry("empty", "", false),
+		Entry("too long", strings.Repeat("A", 256), false),
+		Entry("leading digit", "1IRIS", false),
+		Entry("space", "MY IRIS", false),
+		Entry("path separator", "../IRIS", false),
+		Entry("shell metacharacters", "IRIS;rm -rf /", false),
+	)
+
+	It("Is checked by LoadInstance", func() {
+		_, err := LoadInstance("IRIS;reboot")
+		Expect(err).To(MatchError(ErrInvalidInstanceName))
+	})
+})
+
+var _ = Describe("DefaultCommandTimeout", func() {
+	var instance *Instance
+
+	BeforeEach(func() {
+		script := filepath.Join(GinkgoT().TempDir(), "hang")
+		Expect(os.WriteFile(script, []byte("#!/bin/sh\nexec sleep 10\n"), 0755)).To(Succeed())
+		instance = &Instance{Name: "INSTTEST", SessionPath: script, ControlPath: script}
+		SetDefaultCommandTimeout(100 * time.Millisecond)
+	})
+	AfterEach(func() {
+		SetDefaultCommandTimeout(0)
+	})
+
+	It("Defaults to no timeout", func() {
+		SetDefaultCommandTimeout(0)
+		Expect(DefaultCommandTimeout()).To(BeZero())
+	})
+
+	It("Kills hung imports", func() {
+		_, err := instance.ImportSource("USER", "/tmp/code.xml")
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("Kills hung control commands", func() {
+		_, err := instance.RunControlCommand("stat")
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("Kills hung sessions", func() {
+		_, code, err := instance.RunSession("USER", "^%SS")
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(code).To(Equal(-1))
+	})
+
+	It("Kills session commands when their context is done", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		Expect(instance.SessionCommandContext(ctx, "USER", "^%SS").Run()).To(HaveOccurred())
+		Expect(ctx.Err()).To(MatchError(context.DeadlineExceeded))
+	})
+})
+
+var _ = Describe("LoadInstances", func() {
+	var origIrisPath, origCControlPath string
+
+	writeControl := func(dir, name, output string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte("#!/bin/sh\ncat <<'EOF'\n"+output+"\nEOF\n"), 0755)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		origIrisPath = IrisPath()
+		origCControlPath = CControlPath()
+	})
+	AfterEach(func() {
+		SetIrisPath(origIrisPath)
+		SetCControlPath(origCControlPath)
+	})
+
+	It("Merges the instances listed by each control command", func() {
+		dir := GinkgoT().TempDir()
+		SetIrisPath(writeControl(dir, "iris",
+			"IRIS^/usr/irissys/^2022.1.0.209.0^running, since Fri May 13 22:07:02 2016^iris.cpf^1972^52773^62972^ok^IRIS\n"+
+				"SHARED^/ensemble/instances/shared/^2018.1.1.643.0^down, last used Fri May 13 18:12:33 2016^cache.cpf^56772^57772^62972^^"))
+		SetCControlPath(writeControl(dir, "ccontrol",
+			"SHARED^/ensemble/instances/shared^2018.1.1.643.0^down, last used Fri May 13 18:12:33 2016^cache.cpf^56772^57772^62972^^\n"+
+				"CACHE^/ensemble/instances/cache/^2018.1.1.643.0^down, last used Fri May 13 18:12:33 2016^cache.cpf^56773^57773^62973^^"))
+
+		instances, err := LoadInstances()
+		Expect(err).NotTo(HaveOccurred())
+		names := make([]string, 0, len(instances))
+		for _, instance := range instances {
+			names = append(names, instance.Name)
+		}
+		Expect(names).To(Equal([]string{"IRIS", "SHARED", "CACHE"}))
+	})
+
+	It("Lists the instances of the other control command when one fails", func() {
+		dir := GinkgoT().TempDir()
+		SetIrisPath(writeControl(dir, "iris",
+			"IRIS^/usr/irissys/^2022.1.0.209.0^running, since Fri May 13 22:07:02 2016^iris.cpf^1972^52773^62972^ok^IRIS"))
+		failing := filepath.Join(dir, "ccontrol")
+		Expect(os.WriteFile(failing, []byte("#!/bin/sh\nexit 1\n"), 0755)).To(Succeed())
+		SetCControlPath(failing)
+
+		instances, err := LoadInstances()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instances).To(HaveLen(1))
+		Expect(instances[0].Name).To(Equal("IRIS"))
+	})
+
+	It("Returns an error when every control command fails", func() {
+		dir := GinkgoT().TempDir()
+		failing := filepath.Join(dir, "ccontrol")
+		Expect(os.WriteFile(failing, []byte("#!/bin/sh\nexit 1\n"), 0755)).To(Succeed())
+		SetIrisPath(failing)
+		SetCControlPath(failing)
+
+		_, err := LoadInstances()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Updates an instance only listed by the other control command", func() {
+		// each fake control command only lists the instances of its own product, an unknown status requires an update
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "iris"), []byte(`#!/bin/sh
+[ -z "$2" ] && echo "IRIS^/usr/irissys/^2022.1.0.209.0^running, since Fri May 13 22:07:02 2016^iris.cpf^1972^52773^62972^ok^IRIS"
+exit 0
+`), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "ccontrol"), []byte(`#!/bin/sh
+case "$2" in
+  "") echo "CACHE^/ensemble/instances/cache/^2018.1.1.643.0^^cache.cpf^56773^57773^62973^^" ;;
+  CACHE) echo "CACHE^/ensemble/instances/cache/^2018.1.1.643.0^down, last used Fri May 13 18:12:33 2016^cache.cpf^56773^57773^62973^^" ;;
+  *) exit 1 ;;
+esac
+`), 0755)).To(Succeed())
+		SetIrisPath(filepath.Join(dir, "iris"))
+		SetCControlPath(filepath.Join(dir, "ccontrol"))
+
+		instances, err := LoadInstances()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instances).To(HaveLen(2))
+		Expect(instances[1].Name).To(Equal("CACHE"))
+		Expect(instances[1].Status).To(Equal(InstanceStatusDown))
+	})
+})
+
+var _ = Describe("RegistryPath", func() {
+	var origIrisPath, origCControlPath, origRegistryPath string
+
+	BeforeEach(func() {
+		origIrisPath = IrisPath()
+		origCControlPath = CControlPath()
+		origRegistryPath = RegistryPath()
+		// The fake control command only lists an instance when the registry has been relocated
+		dir := GinkgoT().TempDir()
+		control := filepath.Join(dir, "iris")
+		Expect(os.WriteFile(control, []byte(`#!/bin/sh
+if [ "$IRISSYS" = "/opt/registry" ] && [ "$CACHESYS" = "/opt/registry" ]; then
+  echo "IRIS^`+dir+`^2022.1.0.209.0^down, last used Fri May 13 18:12:33 2016^iris.cpf^1972^52773^62972^^IRIS"
+fi
+`), 0755)).To(Succeed())
+		SetIrisPath(control)
+		SetCControlPath(filepath.Join(dir, "missing"))
+	})
+	AfterEach(func() {
+		SetIrisPath(origIrisPath)
+		SetCControlPath(origCControlPath)
+		SetRegistryPath(origRegistryPath)
+	})
+
+	It("Defaults to the default registry location", func() {
+		Expect(RegistryPath()).To(BeEmpty())
+		instances, err := LoadInstances()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instances).To(BeEmpty())
+	})
+
+	It("Discovers instances in a relocated registry", func() {
+		GinkgoT().Setenv("ISCLIB_TEST_REGISTRY", "/opt/registry")
+		SetRegistryPath("$ISCLIB_TEST_REGISTRY")
+		instances, err := LoadInstances()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instances).To(HaveLen(1))
+		Expect(instances[0].Name).To(Equal("IRIS"))
+	})
+})
+
+var _ = Describe("LoadInstance", func() {
+	var origIrisPath, origCControlPath string
+
+	BeforeEach(func() {
+		origIrisPath = IrisPath()
+		origCControlPath = CControlPath()
+		// The fake control command only lists the CACHE instance
+		dir := GinkgoT().TempDir()
+		control := filepath.Join(dir, "iris")
+		Expect(os.WriteFile(control, []byte(`#!/bin/sh
+case "$2" in
+  [Cc][Aa][Cc][Hh][Ee]) echo "CACHE^`+dir+`^2018.1.1.643.0^down, last used Fri May 13 18:12:33 2016^cache.cpf^56773^57773^62973^^" ;;
+esac
+`), 0755)).To(Succeed())
+		SetIrisPath(control)
+		SetCControlPath(filepath.Join(dir, "missing"))
+	})
+	AfterEach(func() {
+		SetIrisPath(origIrisPath)
+		SetCControlPath(origCControlPath)
+	})
+
+	It("Loads the instance", func() {
+		instance, err := LoadInstance("cache")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance.SuperServerPort).To(Equal(56773))
+		Expect(InstanceExists("CACHE")).To(BeTrue())
+	})
+
+	It("Returns ErrInstanceNotFound for an instance which is not installed", func() {
+		_, err := LoadInstance("NOPE")
+		Expect(err).To(MatchError(ErrInstanceNotFound))
+		Expect(InstanceExists("NOPE")).To(BeFalse())
+	})
+})
+
+var _ = Describe("InstancesFromQListReader", func() {
+	It("Parses every instance listed", func() {
+		instances, err := InstancesFromQListReader(strings.NewReader(
+			"IRIS^/usr/irissys/^2022.1.0.209.0^running, since Fri May 13 22:07:02 2016^iris.cpf^1972^52773^62972^ok^IRIS\n" +
+				"\n" +
+				"CACHE^/ensemble/instances/cache/^2018.1.1.643.0^down, last used Fri May 13 18:12:33 2016^cache.cpf^56773^57773^62973^^\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instances).To(HaveLen(2))
+		Expect(instances[0].Name).To(Equal("IRIS"))
+		Expect(instances[0].Status).To(Equal(InstanceStatusRunning))
+		Expect(instances[0].Product).To(Equal(Iris))
+		Expect(instances[1].Name).To(Equal("CACHE"))
+		Expect(instances[1].SuperServerPort).To(Equal(56773))
+	})
+
+	It("Returns an empty list for empty output", func() {
+		Expect(InstancesFromQListReader(strings.NewReader(""))).To(BeEmpty())
+	})
+
+	It("Returns an error for an invalid line", func() {
+		_, err := InstancesFromQListReader(strings.NewReader("IRIS^/usr/irissys/\n"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("QListDelimiter", func() {
+	AfterEach(func() {
+		Expect(SetQListDelimiter(DefaultQListDelimiter)).To(Succeed())
+	})
+
+	It("Defaults to ^", func() {
+		Expect(QListDelimiter()).To(Equal("^"))
+	})
+
+	It("Parses qlist output using the configured delimiter", func() {
+		Expect(SetQListDelimiter("|")).To(Succeed())
+		instance, err := InstanceFromQList("IRIS|/usr/irissys/|2022.1.0.209.0|running, since Fri May 13 22:07:02 2016|iris.cpf|1972|52773|62972|ok|IRIS")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance.Name).To(Equal("IRIS"))
+		Expect(instance.SuperServerPort).To(Equal(1972))
+		Expect(instance.Product).To(Equal(Iris))
+
+		_, err = InstanceFromQList("IRIS^/usr/irissys/^2022.1.0.209.0^running, since Fri May 13 22:07:02 2016^iris.cpf^1972^52773^62972^ok^IRIS")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Rejects an empty delimiter", func() {
+		Expect(SetQListDelimiter("")).To(MatchError(ErrInvalidQListDelimiter))
+		Expect(QListDelimiter()).To(Equal("^"))
+	})
+})
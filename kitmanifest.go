@@ -0,0 +1,62 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	// The manifests written to the install directory by the installer, in the order they are checked
+	kitManifestFiles = []string{"manifest.isc", "packages.isc"}
+)
+
+// KitManifest will read the manifest of installed components written to the instance's install directory by the
+// installer.  The manifest uses the same format as the parameters file (see LoadParametersISC) and does not require
+// the instance to be running.
+// It returns the manifest values keyed by component (repeated values are joined with a comma) and any error
+// encountered.
+func (i *Instance) KitManifest() (map[string]string, error) {
+	for _, file := range kitManifestFiles {
+		f, err := fileParameterReader(i.Directory, file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		pi, err := LoadParametersISC(f)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read kit manifest %s: %w", file, err)
+		}
+
+		manifest := make(map[string]string)
+		for _, group := range pi {
+			for _, entry := range group {
+				manifest[entry.Key()] = strings.Join(entry.Values, ",")
+			}
+		}
+
+		return manifest, nil
+	}
+
+	return nil, fmt.Errorf("kit manifest not found in %s: %w", i.Directory, os.ErrNotExist)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("KitManifest", func() {
+	var instance *Instance
+
+	BeforeEach(func() {
+		instance = &Instance{Name: "INSTTEST", Directory: GinkgoT().TempDir()}
+	})
+
+	It("Reads the installed components", func() {
+		Expect(os.WriteFile(filepath.Join(instance.Directory, "manifest.isc"), []byte(
+			"product.version: 2018.1.1.643.0\npatch.id: AD123\npatch.id: AD456\ncomponent.csp: 2018.1.1.643.0\n"), 0644)).To(Succeed())
+		Expect(instance.KitManifest()).To(Equal(map[string]string{
+			"product.version": "2018.1.1.643.0",
+			"patch.id":        "AD123,AD456",
+			"component.csp":   "2018.1.1.643.0",
+		}))
+	})
+
+	It("Falls back to the packages manifest", func() {
+		Expect(os.WriteFile(filepath.Join(instance.Directory, "packages.isc"), []byte("database_server: 2018.1.1.643.0\n"), 0644)).To(Succeed())
+		Expect(instance.KitManifest()).To(Equal(map[string]string{"database_server": "2018.1.1.643.0"}))
+	})
+
+	It("Returns an error when there is no manifest", func() {
+		_, err := instance.KitManifest()
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+
+	It("Returns an error when the manifest is malformed", func() {
+		Expect(os.WriteFile(filepath.Join(instance.Directory, "manifest.isc"), []byte("nope\n"), 0644)).To(Succeed())
+		_, err := instance.KitManifest()
+		Expect(err).To(MatchError(ContainSubstring("malformed parameter line")))
+	})
+})
@@ -0,0 +1,230 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	licenseSection       = "License"
+	licenseCapacityKey   = "LicenseCapacity"
+	licenseExpirationKey = "ExpirationDate"
+	// The expiration date of a license key is month/day/year
+	licenseExpirationLayout = "1/2/2006"
+	// The permissions of a newly installed license key
+	licenseKeyPermissions fs.FileMode = 0644
+
+	// Activates the installed license key in the running instance
+	licenseUpgradeCode = `MAIN
+ set sc=$system.License.Upgrade()
+ if 'sc write "ERROR:",$system.Status.GetErrorText(sc),! quit
+ quit
+
+`
+)
+
+var (
+	// ErrLicenseLimitNotFound is an error signifying that the license key does not contain a concurrent user limit
+	ErrLicenseLimitNotFound = errors.New("license key does not contain a concurrent user limit")
+	// ErrLicenseExpirationNotFound is an error signifying that the license key does not contain an expiration date
+	ErrLicenseExpirationNotFound = errors.New("license key does not contain an expiration date")
+	// ErrInvalidLicenseKey is an error signifying that the content provided as a license key is not a license key
+	ErrInvalidLicenseKey = errors.New("invalid license key")
+
+	licenseLimitRegexp = regexp.MustCompile(`Concurrent Users[^:,]*:\s*(\d+)`)
+)
+
+// LicenseLimit will parse the instance's license key for the number of concurrent users it allows.
+// It returns the limit and any error encountered.
+func (i *Instance) LicenseLimit() (int, error) {
+	key, err := i.loadLicenseKey()
+	if err != nil {
+		return 0, err
+	}
+
+	capacity, _ := key.Value(licenseSection, licenseCapacityKey)
+	m := licenseLimitRegexp.FindStringSubmatch(capacity)
+	if m == nil {
+		return 0, ErrLicenseLimitNotFound
+	}
+
+	return strconv.Atoi(m[1])
+}
+
+// LicenseExpired will parse the instance's license key for its expiration date and compare it to the current time.
+// The key is valid through the end of its expiration date (in the local time zone).
+// It returns whether the key has expired, the expiration date and any error encountered.
+func (i *Instance) LicenseExpired() (bool, time.Time, error) {
+	key, err := i.loadLicenseKey()
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	value, _ := key.Value(licenseSection, licenseExpirationKey)
+	if value = strings.TrimSpace(value); value == "" {
+		return false, time.Time{}, ErrLicenseExpirationNotFound
+	}
+
+	expiration, err := time.ParseInLocation(licenseExpirationLayout, value, time.Local)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid license key expiration date %q: %w", value, err)
+	}
+
+	return !time.Now().Before(expiration.AddDate(0, 0, 1)), expiration, nil
+}
+
+// InstallLicenseKey will install the provided license key as the instance's license key (see LicenseKeyFilePath).
+// The key is written alongside the existing key and renamed over it so the key is never left partially written.  The
+// permissions of an existing key are preserved and the key is owned by the instance owner (see DetermineOwner).
+// When the instance is running the key is also activated ($SYSTEM.License.Upgrade), otherwise it is activated by the
+// next start.
+// It returns an error wrapping ErrInvalidLicenseKey if the content does not contain a [License] section and any other
+// error encountered.
+func (i *Instance) InstallLicenseKey(keyContent []byte) error {
+	key, err := ParseCPF(bytes.NewReader(keyContent))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidLicenseKey, err)
+	}
+
+	if key.section(licenseSection) == nil {
+		return fmt.Errorf("%w: missing [%s] section", ErrInvalidLicenseKey, licenseSection)
+	}
+
+	if err := i.writeLicenseKey(keyContent); err != nil {
+		return fmt.Errorf("unable to install license key: %w", err)
+	}
+
+	if !i.Status.Up() {
+		return nil
+	}
+
+	out, err := i.ExecuteString(systemNamespace, licenseUpgradeCode)
+	if err != nil {
+		return err
+	}
+
+	if err := configOutputError(out); err != nil {
+		return fmt.Errorf("unable to activate license key: %w", err)
+	}
+
+	return nil
+}
+
+// writeLicenseKey replaces the instance's license key file with the provided content owned by the instance owner.
+// Like rewriteCPF, an existing key is overwritten in place when the caller may write it without being able to give the
+// replacement to the instance owner.
+func (i *Instance) writeLicenseKey(keyContent []byte) (err error) {
+	keyPath := i.LicenseKeyFilePath()
+	perm := licenseKeyPermissions
+	info, statErr := FS.Stat(keyPath)
+	if statErr == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmpFile, err := afero.TempFile(FS, filepath.Dir(keyPath), "keytemp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = FS.Remove(tmpFile.Name())
+		}
+	}()
+
+	if _, err = tmpFile.Write(keyContent); err != nil {
+		tmpFile.Close()
+		return err
+	}
+
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+
+	if err = FS.Chmod(tmpFile.Name(), perm); err != nil {
+		return err
+	}
+
+	if switchUsersSupported {
+		uid, gid, err := i.ownerIDs()
+		if err != nil {
+			return err
+		}
+
+		preserved, err := changeOwnership(tmpFile.Name(), uint32(uid), uint32(gid))
+		if err != nil {
+			return err
+		}
+
+		if !preserved {
+			if statErr == nil {
+				return overwriteFile(tmpFile.Name(), keyPath)
+			}
+			log.WithFields(log.Fields{"path": keyPath, "uid": uid, "gid": gid}).Warn("Unable to give the license key to the instance owner")
+		}
+	}
+
+	return FS.Rename(tmpFile.Name(), keyPath)
+}
+
+// ownerIDs returns the user and group IDs of the instance owner (see DetermineOwner)
+func (i *Instance) ownerIDs() (uid, gid int, err error) {
+	owner, group, err := i.DetermineOwner()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ownerUID, _, err := lookupUser(owner)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	groupGID, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int(ownerUID), groupGID, nil
+}
+
+// loadLicenseKey reads the instance's license key which uses the same format as a CPF
+func (i *Instance) loadLicenseKey() (*CPF, error) {
+	file, err := FS.Open(i.LicenseKeyFilePath())
+	if err != nil {
+		return nil, fmt.Errorf("unable to read license key: %w", err)
+	}
+	defer file.Close()
+
+	return ParseCPF(file)
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("License", func() {
+	var (
+		instance *Instance
+		origFS   afero.Fs
+	)
+
+	writeKey := func(content string) {
+		Expect(afero.WriteFile(FS, instance.LicenseKeyFilePath(), []byte(content), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		origFS = FS
+		FS = new(afero.MemMapFs)
+		instance = &Instance{DataDirectory: "/test/durable", Product: Iris}
+		Expect(FS.MkdirAll(instance.MgrDirectory(), 0755)).To(Succeed())
+	})
+	AfterEach(func() {
+		FS = origFS
+	})
+
+	Context("LicenseLimit", func() {
+		It("Returns the concurrent user limit of an IRIS key", func() {
+			writeKey("[ConfigFile]\nFileType=License 2021.1\n\n[License]\nLicenseCapacity=InterSystems IRIS 2021.1 Enterprise - Concurrent Users for x86-64 (Linux):128, Sharding, Mirroring\nExpirationDate=12/31/2030\n")
+			Expect(instance.LicenseLimit()).To(Equal(128))
+		})
+
+		It("Returns the concurrent user limit of a Cache key", func() {
+			instance.Product = Cache
+			writeKey("[ConfigFile]\nFileType=License 2017.1\n\n[License]\nLicenseCapacity=Cache 2017.1 Enterprise - Concurrent Users:25, Web Services\n")
+			Expect(instance.LicenseLimit()).To(Equal(25))
+		})
+
+		It("Returns an error when the key has no limit", func() {
+			writeKey("[License]\nLicenseCapacity=InterSystems IRIS Community\n")
+			_, err := instance.LicenseLimit()
+			Expect(err).To(MatchError(ErrLicenseLimitNotFound))
+		})
+
+		It("Returns an error when there is no key", func() {
+			_, err := instance.LicenseLimit()
+			Expect(err).To(MatchError(os.ErrNotExist))
+		})
+	})
+
+	Context("LicenseExpired", func() {
+		It("Returns the expiration date of a current key", func() {
+			writeKey("[License]\nLicenseCapacity=InterSystems IRIS 2021.1 Enterprise - Concurrent Users:128\nExpirationDate=12/31/2099\n")
+			expired, expiration, err := instance.LicenseExpired()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(expired).To(BeFalse())
+			Expect(expiration).To(Equal(time.Date(2099, time.December, 31, 0, 0, 0, 0, time.Local)))
+		})
+
+		It("Reports an expired key", func() {
+			writeKey("[License]\nExpirationDate=1/5/2020\n")
+			expired, expiration, err := instance.LicenseExpired()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(expired).To(BeTrue())
+			Expect(expiration).To(Equal(time.Date(2020, time.January, 5, 0, 0, 0, 0, time.Local)))
+		})
+
+		It("Considers the key valid through its expiration date", func() {
+			writeKey("[License]\nExpirationDate=" + time.Now().Format("01/02/2006") + "\n")
+			expired, _, err := instance.LicenseExpired()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(expired).To(BeFalse())
+		})
+
+		It("Returns an error when the key has no expiration date", func() {
+			writeKey("[License]\nLicenseCapacity=InterSystems IRIS Community\n")
+			_, _, err := instance.LicenseExpired()
+			Expect(err).To(MatchError(ErrLicenseExpirationNotFound))
+		})
+
+		It("Returns an error when the expiration date is invalid", func() {
+			writeKey("[License]\nExpirationDate=someday\n")
+			_, _, err := instance.LicenseExpired()
+			Expect(err).To(MatchError(ContainSubstring(`"someday"`)))
+		})
+	})
+})
+
+var _ = Describe("InstallLicenseKey", func() {
+	const key = "[ConfigFile]\nFileType=License 2021.1\n\n[License]\nLicenseCapacity=InterSystems IRIS 2021.1 Enterprise - Concurrent Users:64\n"
+	var (
+		instance            *Instance
+		cur                 *user.User
+		origFS              afero.Fs
+		origParameterReader func(string, string) (io.ReadCloser, error)
+	)
+
+	// The fake session successfully imports the code and writes the provided output when it is run
+	writeSession := func(output string) {
+		instance.SessionPath = WriteFakeSession(FakeSession{Main: "printf '" + output + "'"})
+	}
+
+	BeforeEach(func() {
+		// the key is installed to and the owner read from the real file system
+		origFS = FS
+		FS = afero.NewOsFs()
+		origParameterReader = parameterReader
+		parameterReader = fileParameterReader
+
+		var err error
+		cur, err = user.Current()
+		Expect(err).NotTo(HaveOccurred())
+		group, err := user.LookupGroupId(cur.Gid)
+		Expect(err).NotTo(HaveOccurred())
+
+		dir := GinkgoT().TempDir()
+		instance = &Instance{Name: "INSTTEST", Directory: dir, DataDirectory: dir, Product: Iris, Status: InstanceStatusDown}
+		parameters := "security_settings.iris_user: " + cur.Username + "\nsecurity_settings.iris_group: " + group.Name + "\n"
+		Expect(os.WriteFile(filepath.Join(dir, iscParametersFile), []byte(parameters), 0644)).To(Succeed())
+		Expect(os.MkdirAll(instance.MgrDirectory(), 0755)).To(Succeed())
+		SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+	})
+	AfterEach(func() {
+		FS = origFS
+		parameterReader = origParameterReader
+		SetExecuteTemporaryDirectory("")
+	})
+
+	It("Installs the key owned by the instance owner", func() {
+		Expect(instance.InstallLicenseKey([]byte(key))).To(Succeed())
+		Expect(instance.LicenseLimit()).To(Equal(64))
+		info, err := os.Stat(instance.LicenseKeyFilePath())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0644)))
+		uid, _, ok := fileOwnership(info)
+		Expect(ok).To(BeTrue())
+		Expect(fmt.Sprint(uid)).To(Equal(cur.Uid))
+		entries, err := os.ReadDir(instance.MgrDirectory())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1), "temporary files are removed")
+	})
+
+	It("Replaces an existing key preserving its permissions", func() {
+		Expect(os.WriteFile(instance.LicenseKeyFilePath(), []byte("[License]\nLicenseCapacity=InterSystems IRIS 2021.1 Enterprise - Concurrent Users:8\n"), 0600)).To(Succeed())
+		Expect(instance.InstallLicenseKey([]byte(key))).To(Succeed())
+		Expect(instance.LicenseLimit()).To(Equal(64))
+		info, err := os.Stat(instance.LicenseKeyFilePath())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("Rejects content which is not a license key", func() {
+		err := instance.InstallLicenseKey([]byte("not a key"))
+		Expect(err).To(MatchError(ErrInvalidLicenseKey))
+		Expect(instance.LicenseKeyFilePath()).NotTo(BeAnExistingFile())
+	})
+
+	It("Returns an error when the owner cannot be determined", func() {
+		Expect(os.Remove(filepath.Join(instance.Directory, iscParametersFile))).To(Succeed())
+		err := instance.InstallLicenseKey([]byte(key))
+		var pIscErr *ParametersISCError
+		Expect(errors.As(err, &pIscErr)).To(BeTrue())
+		Expect(instance.LicenseKeyFilePath()).NotTo(BeAnExistingFile())
+		entries, err := os.ReadDir(instance.MgrDirectory())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty(), "temporary files are removed")
+	})
+
+	It("Activates the key in a running instance", func() {
+		writeSession(``)
+		instance.Status = InstanceStatusRunning
+		Expect(instance.InstallLicenseKey([]byte(key))).To(Succeed())
+		Expect(instance.LicenseLimit()).To(Equal(64))
+	})
+
+	Context("Without permission to give the key to the instance owner", func() {
+		BeforeEach(func() {
+			FS = chownRefusingFs{afero.NewOsFs()}
+			group, err := user.LookupGroupId("65534")
+			if err != nil {
+				Skip("the instance owner must have a group other than the test user's")
+			}
+			parameters := "security_settings.iris_user: " + cur.Username + "\nsecurity_settings.iris_group: " + group.Name + "\n"
+			Expect(os.WriteFile(filepath.Join(instance.Directory, iscParametersFile), []byte(parameters), 0644)).To(Succeed())
+		})
+
+		It("Overwrites an existing key in place", func() {
+			Expect(os.WriteFile(instance.LicenseKeyFilePath(), []byte("[License]\nLicenseCapacity=InterSystems IRIS 2021.1 Enterprise - Concurrent Users:8\n"), 0640)).To(Succeed())
+			before, err := os.Stat(instance.LicenseKeyFilePath())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance.InstallLicenseKey([]byte(key))).To(Succeed())
+			Expect(instance.LicenseLimit()).To(Equal(64))
+			after, err := os.Stat(instance.LicenseKeyFilePath())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.SameFile(before, after)).To(BeTrue())
+			entries, err := os.ReadDir(instance.MgrDirectory())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1), "temporary files are removed")
+		})
+
+		It("Installs a new key owned by the caller", func() {
+			Expect(instance.InstallLicenseKey([]byte(key))).To(Succeed())
+			Expect(instance.LicenseLimit()).To(Equal(64))
+		})
+	})
+
+	It("Returns an error when the key cannot be activated", func() {
+		writeSession(`ERROR:#3007: License key is not valid\n`)
+		instance.Status = InstanceStatusRunning
+		err := instance.InstallLicenseKey([]byte(key))
+		Expect(err).To(MatchError(ContainSubstring("#3007: License key is not valid")))
+	})
+})
+
+// chownRefusingFs is a file system on which the caller is not permitted to change the ownership of files
+type chownRefusingFs struct {
+	afero.Fs
+}
+
+func (fs chownRefusingFs) Chown(name string, uid, gid int) error {
+	return &os.PathError{Op: "chown", Path: name, Err: os.ErrPermission}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// The Config classes in %SYS expose the running configuration, one class per CPF section
+	liveConfigCode = `MAIN
+ set sc=$classmethod("Config.%s","Get",.props)
+ if 'sc write "ERROR:",$system.Status.GetErrorText(sc),! quit
+ set key="" for { set key=$order(props(key),1,value) quit:key=""  write "CONFIG:",key,"=",value,! }
+ quit
+
+`
+	pendingRestartCode = `MAIN
+ if ##class(Config.CPF).PendingRestart(.reasons) {
+ set key="" for { set key=$order(reasons(key),1,value) quit:key=""  write "RESTART:",$select(value'="":value,1:key),! }
+ }
+ quit
+
+`
+	// Activating the CPF applies the changes which can be applied live, any others are reported as pending a restart
+	activateConfigCode = `MAIN
+ set sc=##class(Config.CPF).Activate()
+ if 'sc write "ERROR:",$system.Status.GetErrorText(sc),! quit
+ if ##class(Config.CPF).PendingRestart(.reasons) {
+ set key="" for { set key=$order(reasons(key),1,value) quit:key=""  write "RESTART:",$select(value'="":value,1:key),! }
+ }
+ quit
+
+`
+)
+
+var (
+	// ErrRestartRequired is an error signifying that configuration changes will not take effect until the instance is restarted
+	ErrRestartRequired = errors.New("configuration changes require a restart")
+	// ErrInvalidConfigSection is an error signifying that a configuration section name is not valid
+	ErrInvalidConfigSection = errors.New("invalid configuration section")
+
+	configSectionRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+	liveConfigRegexp    = regexp.MustCompile(`(?m)^CONFIG:([^=\r\n]*)=(.*?)\r?$`)
+	configErrorRegexp   = regexp.MustCompile(`(?m)^ERROR:(.*?)\r?$`)
+	restartReasonRegexp = regexp.MustCompile(`(?m)^RESTART:(.*?)\r?$`)
+)
+
+// LiveConfig will query the running instance for the active values of the provided configuration section (e.g.
+// "Startup" or "config") rather than reading the CPF on disk which may contain changes which have not been activated.
+// Only sections consisting of a single set of values are supported (e.g. not "Namespaces" or "Databases").
+// It returns the values in the section keyed by setting name and any error encountered.
+func (i *Instance) LiveConfig(section string) (map[string]string, error) {
+	if !configSectionRegexp.MatchString(section) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidConfigSection, section)
+	}
+
+	log.WithFields(log.Fields{"instance": i.Name, "section": section}).Debug("Reading live configuration")
+	out, err := i.ExecuteString(systemNamespace, fmt.Sprintf(liveConfigCode, section))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configOutputError(out); err != nil {
+		return nil, fmt.Errorf("unable to read live configuration section %s: %w", section, err)
+	}
+
+	values := make(map[string]string)
+	for _, m := range liveConfigRegexp.FindAllStringSubmatch(out, -1) {
+		values[m[1]] = m[2]
+	}
+
+	return values, nil
+}
+
+// ActivateConfig will apply changes made to the instance's CPF to the running instance without restarting it.
+// Not every setting can be changed while the instance is running, the changes to those settings are left pending.
+// It returns an error wrapping ErrRestartRequired listing the settings responsible when changes are left pending
+// or any other error encountered.
+func (i *Instance) ActivateConfig() error {
+	log.WithField("instance", i.Name).Debug("Activating configuration")
+	out, err := i.ExecuteString(systemNamespace, activateConfigCode)
+	if err != nil {
+		return err
+	}
+
+	if err := configOutputError(out); err != nil {
+		return fmt.Errorf("unable to activate configuration: %w", err)
+	}
+
+	if reasons := restartReasons(out); len(reasons) > 0 {
+		return fmt.Errorf("%w: %s", ErrRestartRequired, strings.Join(reasons, ", "))
+	}
+
+	return nil
+}
+
+// RestartRequired will determine whether the instance has configuration changes which will not take effect until it is
+// restarted (e.g. changes which could not be applied by ActivateConfig).
+// It returns whether a restart is required, the settings responsible and any error encountered.
+func (i *Instance) RestartRequired() (bool, []string, error) {
+	out, err := i.ExecuteString(systemNamespace, pendingRestartCode)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if err := configOutputError(out); err != nil {
+		return false, nil, fmt.Errorf("unable to determine pending configuration changes: %w", err)
+	}
+
+	reasons := restartReasons(out)
+	return len(reasons) > 0, reasons, nil
+}
+
+// restartReasons returns the settings reported by the configuration code as requiring a restart
+func restartReasons(out string) []string {
+	var reasons []string
+	for _, m := range restartReasonRegexp.FindAllStringSubmatch(out, -1) {
+		reasons = append(reasons, strings.TrimSpace(m[1]))
+	}
+
+	return reasons
+}
+
+// configOutputError returns an error for any error reported by the configuration code or exception raised running it
+func configOutputError(out string) error {
+	if m := configErrorRegexp.FindStringSubmatch(out); m != nil {
+		return errors.New(strings.TrimSpace(m[1]))
+	}
+
+	if strings.Contains(out, "Exception: ") {
+		return fmt.Errorf("exception running configuration code, output: %s", out)
+	}
+
+	return nil
+}
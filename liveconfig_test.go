@@ -0,0 +1,109 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("Live configuration", func() {
+	var instance *Instance
+
+	// The fake session successfully imports the code and writes the provided output when it is run
+	writeSession := func(output string) {
+		instance.SessionPath = WriteFakeSession(FakeSession{Main: "printf '" + output + "'"})
+	}
+
+	BeforeEach(func() {
+		SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+		instance = &Instance{Name: "INSTTEST"}
+	})
+	AfterEach(func() {
+		SetExecuteTemporaryDirectory("")
+	})
+
+	Describe("LiveConfig", func() {
+		It("Returns the live values of the section", func() {
+			writeSession(`CONFIG:DefaultPort=1972\nCONFIG:ShutdownTimeout=300\nCONFIG:WebServer=\n`)
+			Expect(instance.LiveConfig("Startup")).To(Equal(map[string]string{
+				"DefaultPort":     "1972",
+				"ShutdownTimeout": "300",
+				"WebServer":       "",
+			}))
+		})
+
+		It("Returns an error reported by the instance", func() {
+			writeSession(`ERROR:#5001: Invalid section\n`)
+			_, err := instance.LiveConfig("Startup")
+			Expect(err).To(MatchError(ContainSubstring("#5001: Invalid section")))
+		})
+
+		It("Returns an error when the section does not exist", func() {
+			writeSession(`\nException: <CLASS DOES NOT EXIST>\n`)
+			_, err := instance.LiveConfig("Nope")
+			Expect(err).To(MatchError(ContainSubstring("CLASS DOES NOT EXIST")))
+		})
+
+		It("Rejects invalid section names", func() {
+			_, err := instance.LiveConfig(`Startup","Get") kill ^X //`)
+			Expect(err).To(MatchError(ErrInvalidConfigSection))
+		})
+	})
+
+	Describe("ActivateConfig", func() {
+		It("Succeeds when all changes were applied", func() {
+			writeSession(``)
+			Expect(instance.ActivateConfig()).To(Succeed())
+		})
+		It("Returns ErrRestartRequired listing the pending settings", func() {
+			writeSession(`RESTART:Startup.DefaultPort\nRESTART:config.globals\n`)
+			err := instance.ActivateConfig()
+			Expect(err).To(MatchError(ErrRestartRequired))
+			Expect(err).To(MatchError(ContainSubstring("Startup.DefaultPort, config.globals")))
+		})
+		It("Returns an error reported by the instance", func() {
+			writeSession(`ERROR:#5001: Invalid CPF\n`)
+			err := instance.ActivateConfig()
+			Expect(err).To(MatchError(ContainSubstring("#5001: Invalid CPF")))
+			Expect(err).NotTo(MatchError(ErrRestartRequired))
+		})
+	})
+
+	Describe("RestartRequired", func() {
+		It("Reports no restart when nothing is pending", func() {
+			writeSession(``)
+			required, reasons, err := instance.RestartRequired()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(required).To(BeFalse())
+			Expect(reasons).To(BeEmpty())
+		})
+		It("Reports the settings requiring a restart", func() {
+			writeSession(`RESTART:Startup.DefaultPort\n`)
+			required, reasons, err := instance.RestartRequired()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(required).To(BeTrue())
+			Expect(reasons).To(Equal([]string{"Startup.DefaultPort"}))
+		})
+		It("Returns an exception raised by the instance", func() {
+			writeSession(`\nException: <METHOD DOES NOT EXIST>\n`)
+			_, _, err := instance.RestartRequired()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
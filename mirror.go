@@ -0,0 +1,234 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MirrorMemberTypeFailover is the mirror member type of a synchronous failover member
+	MirrorMemberTypeFailover = "Failover"
+	// MirrorMemberTypeDisasterRecovery is the mirror member type of an async disaster recovery member
+	MirrorMemberTypeDisasterRecovery = "Disaster Recovery"
+	// MirrorMemberTypeReadOnlyReporting is the mirror member type of an async read-only reporting member
+	MirrorMemberTypeReadOnlyReporting = "Read-Only Reporting"
+	// MirrorMemberTypeReadWriteReporting is the mirror member type of an async read-write reporting member
+	MirrorMemberTypeReadWriteReporting = "Read-Write Reporting"
+
+	// MirrorStatusPrimary is the mirror status of the failover member currently acting as the primary
+	MirrorStatusPrimary = "Primary"
+	// MirrorStatusBackup is the mirror status of the failover member ready to take over from the primary
+	MirrorStatusBackup = "Backup"
+	// MirrorStatusConnected is the mirror status of an async member connected to the primary
+	MirrorStatusConnected = "Connected"
+	// MirrorStatusInTrouble is the mirror status of a failover member which has lost its connection to the other
+	// failover member or the arbiter
+	MirrorStatusInTrouble = "In Trouble"
+	// MirrorStatusTransition is the mirror status of a member which is changing status (e.g. becoming the primary)
+	MirrorStatusTransition = "Transition"
+	// MirrorStatusSynchronizing is the mirror status of a member catching up with the primary's journal files
+	MirrorStatusSynchronizing = "Synchronizing"
+	// MirrorStatusWaiting is the mirror status of a member waiting to connect to the primary
+	MirrorStatusWaiting = "Waiting"
+	// MirrorStatusDisconnected is the mirror status of an async member which is not connected to the primary
+	MirrorStatusDisconnected = "Disconnected"
+	// MirrorStatusStopped is the mirror status of a member whose mirroring has been stopped
+	MirrorStatusStopped = "Stopped"
+	// MirrorStatusCrashed is the mirror status of a member whose mirroring has failed unexpectedly
+	MirrorStatusCrashed = "Crashed"
+	// MirrorStatusError is the mirror status of a member which encountered an error while mirroring
+	MirrorStatusError = "Error"
+	// MirrorStatusDown is the mirror status of a member which is down
+	MirrorStatusDown = "Down"
+	// MirrorStatusNotInitialized is the mirror status of a member whose mirroring has not been initialized
+	MirrorStatusNotInitialized = "Not Initialized"
+)
+
+const (
+	// mirrorsSection is the CPF section listing the mirrors the instance is a member of, keyed by mirror name
+	mirrorsSection = "Mirrors"
+)
+
+var (
+	// ErrNotMirrored is an error signifying that the instance is not configured as a member of a mirror
+	ErrNotMirrored = errors.New("the instance is not a mirror member")
+)
+
+// knownMirrorStatuses are the statuses the qlist mirror status is normalized to
+var knownMirrorStatuses = []MirrorStatus{
+	MirrorStatusPrimary,
+	MirrorStatusBackup,
+	MirrorStatusConnected,
+	MirrorStatusInTrouble,
+	MirrorStatusTransition,
+	MirrorStatusSynchronizing,
+	MirrorStatusWaiting,
+	MirrorStatusDisconnected,
+	MirrorStatusStopped,
+	MirrorStatusCrashed,
+	MirrorStatusError,
+	MirrorStatusDown,
+	MirrorStatusNotInitialized,
+}
+
+// MirrorMemberType is the type of an instance's mirror membership (see the MirrorMemberType constants)
+type MirrorMemberType string
+
+// MirrorStatus is the status of an instance within its mirror (see the MirrorStatus constants)
+type MirrorStatus string
+
+// IsPrimary returns true when the status is that of the primary failover member
+func (s MirrorStatus) IsPrimary() bool {
+	return s == MirrorStatusPrimary
+}
+
+// IsConnected returns true when the status is that of a member connected to the primary (the backup or a connected
+// async member)
+func (s MirrorStatus) IsConnected() bool {
+	return s == MirrorStatusBackup || s == MirrorStatusConnected
+}
+
+// IsInTrouble returns true when the status is that of a failover member which has lost contact with the other failover
+// member or the arbiter
+func (s MirrorStatus) IsInTrouble() bool {
+	return s == MirrorStatusInTrouble
+}
+
+// MirrorInfo describes an instance's mirror membership
+type MirrorInfo struct {
+	// The type of mirror member, empty if the instance is not mirrored
+	MemberType MirrorMemberType
+	// The status of the member within the mirror
+	Status MirrorStatus
+}
+
+// Mirror returns the instance's mirror membership as reported by qlist
+func (i *Instance) Mirror() MirrorInfo {
+	return MirrorInfo{
+		MemberType: MirrorMemberType(i.MirrorMemberType),
+		Status:     parseMirrorStatus(i.MirrorStatus),
+	}
+}
+
+// IsMirrored returns true when the instance is a member of a mirror
+func (m MirrorInfo) IsMirrored() bool {
+	return m.MemberType != ""
+}
+
+// IsPrimary returns true when the instance is the primary failover member of its mirror
+func (m MirrorInfo) IsPrimary() bool {
+	return m.MemberType == MirrorMemberTypeFailover && m.Status.IsPrimary()
+}
+
+// IsAsync returns true when the instance is an async (disaster recovery or reporting) mirror member
+func (m MirrorInfo) IsAsync() bool {
+	switch m.MemberType {
+	default:
+		return false
+	case
+		MirrorMemberTypeDisasterRecovery,
+		MirrorMemberTypeReadOnlyReporting,
+		MirrorMemberTypeReadWriteReporting:
+		return true
+	}
+}
+
+// MirrorName will read the name of the mirror the instance is a member of from the [Mirrors] section of its CPF.
+// The [MirrorMember] SystemName is the name of this member within the mirror, not the name of the mirror.  Reporting
+// async members may belong to several mirrors, the first one configured is returned.
+// It returns the mirror name and any error encountered, including ErrNotMirrored if no mirror is configured.
+func (i *Instance) MirrorName() (string, error) {
+	cpf, err := i.LoadCPF()
+	if err != nil {
+		return "", err
+	}
+
+	if s := cpf.section(mirrorsSection); s != nil {
+		for _, e := range s.Entries {
+			if name := strings.TrimSpace(e.Key); name != "" {
+				return name, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w, instance: %s", ErrNotMirrored, i.Name)
+}
+
+// MirrorSplitBrainCheck inspects the mirror status of the members of a mirror (as last updated, see Instances.Update)
+// and reports whether more than one of them claims to be the primary, a split-brain which must be resolved before
+// any failover is attempted.
+// It returns true when more than one member is the primary and an error wrapping ErrNotMirrored if any of the
+// instances is not a mirror member.
+func MirrorSplitBrainCheck(members Instances) (bool, error) {
+	primaries := 0
+	for _, member := range members {
+		if member == nil {
+			continue
+		}
+
+		m := member.Mirror()
+		if !m.IsMirrored() {
+			return false, fmt.Errorf("%w, instance: %s", ErrNotMirrored, member.Name)
+		}
+
+		if m.Status.IsPrimary() {
+			primaries++
+		}
+	}
+
+	return primaries > 1, nil
+}
+
+// IsAsyncMirrorMember returns true when the instance is an async (disaster recovery or reporting) mirror member
+func (i *Instance) IsAsyncMirrorMember() bool {
+	return i.Mirror().IsAsync()
+}
+
+// parseMirrorMemberType normalizes the variations of the mirror member types (case, abbreviations and an "async"
+// qualifier) to the MirrorMemberType constants.  Unrecognized values are returned unchanged.
+func parseMirrorMemberType(memberType string) string {
+	t := strings.ToLower(strings.TrimSpace(memberType))
+	t = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(t, "async"), "async"))
+	switch t {
+	case "failover":
+		return MirrorMemberTypeFailover
+	case "disaster recovery", "dr":
+		return MirrorMemberTypeDisasterRecovery
+	case "read-only reporting", "read only reporting", "reporting read-only", "ro reporting":
+		return MirrorMemberTypeReadOnlyReporting
+	case "read-write reporting", "read write reporting", "reporting read-write", "rw reporting":
+		return MirrorMemberTypeReadWriteReporting
+	default:
+		return memberType
+	}
+}
+
+// parseMirrorStatus normalizes the case and spacing of the known mirror statuses to the MirrorStatus constants (e.g.
+// "in trouble" and "InTrouble" are MirrorStatusInTrouble).  Unrecognized values are returned unchanged.
+func parseMirrorStatus(status string) MirrorStatus {
+	s := strings.ReplaceAll(strings.TrimSpace(status), " ", "")
+	for _, known := range knownMirrorStatuses {
+		if strings.EqualFold(s, strings.ReplaceAll(string(known), " ", "")) {
+			return known
+		}
+	}
+
+	return MirrorStatus(status)
+}
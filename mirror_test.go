@@ -0,0 +1,162 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("Mirror", func() {
+	DescribeTable("Mirror member type", func(memberType string, expected string, async bool) {
+		i := new(isclib.Instance)
+		err := i.UpdateFromQList("INSTTEST^/ensemble/instances/insttest/^2015.2.2.805.0.16216^running, since Fri May 13 22:07:02 2016^cache.cpf^56772^57772^62972^ok^^" + memberType + "^Connected^/mgr/config")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(i.MirrorMemberType).To(Equal(expected), "member type")
+		Expect(i.IsAsyncMirrorMember()).To(Equal(async), "async")
+	},
+		Entry("is not mirrored", "", "", false),
+		Entry("is failover", "Failover", isclib.MirrorMemberTypeFailover, false),
+		Entry("is disaster recovery", "Disaster Recovery", isclib.MirrorMemberTypeDisasterRecovery, true),
+		Entry("is disaster recovery async", "Async Disaster Recovery", isclib.MirrorMemberTypeDisasterRecovery, true),
+		Entry("is abbreviated disaster recovery", "DR", isclib.MirrorMemberTypeDisasterRecovery, true),
+		Entry("is read-only reporting", "Read-Only Reporting", isclib.MirrorMemberTypeReadOnlyReporting, true),
+		Entry("is read-write reporting", "read-write reporting", isclib.MirrorMemberTypeReadWriteReporting, true),
+		Entry("is read-write reporting async", "Read-Write Reporting Async", isclib.MirrorMemberTypeReadWriteReporting, true),
+		Entry("is unknown", "Something Else", "Something Else", false),
+	)
+
+	DescribeTable("Mirror info", func(memberType, status string, expected isclib.MirrorInfo, primary bool) {
+		i := new(isclib.Instance)
+		err := i.UpdateFromQList("INSTTEST^/ensemble/instances/insttest/^2015.2.2.805.0.16216^running, since Fri May 13 22:07:02 2016^cache.cpf^56772^57772^62972^ok^^" + memberType + "^" + status + "^/mgr/config")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(i.Mirror()).To(Equal(expected))
+		Expect(i.Mirror().IsPrimary()).To(Equal(primary), "primary")
+		Expect(i.Mirror().IsMirrored()).To(Equal(memberType != ""), "mirrored")
+	},
+		Entry("is not mirrored", "", "", isclib.MirrorInfo{}, false),
+		Entry("is the primary", "Failover", "Primary", isclib.MirrorInfo{MemberType: isclib.MirrorMemberTypeFailover, Status: isclib.MirrorStatusPrimary}, true),
+		Entry("is the backup", "Failover", "backup", isclib.MirrorInfo{MemberType: isclib.MirrorMemberTypeFailover, Status: isclib.MirrorStatusBackup}, false),
+		Entry("is a connected async", "DR", "Connected", isclib.MirrorInfo{MemberType: isclib.MirrorMemberTypeDisasterRecovery, Status: isclib.MirrorStatusConnected}, false),
+		Entry("is in transition", "Failover", "Transition", isclib.MirrorInfo{MemberType: isclib.MirrorMemberTypeFailover, Status: isclib.MirrorStatusTransition}, false),
+		Entry("has an unknown status", "Failover", "Something Else", isclib.MirrorInfo{MemberType: isclib.MirrorMemberTypeFailover, Status: "Something Else"}, false),
+	)
+
+	DescribeTable("Mirror status", func(status, expected string, primary, connected, inTrouble bool) {
+		i := new(isclib.Instance)
+		err := i.UpdateFromQList("INSTTEST^/ensemble/instances/insttest/^2015.2.2.805.0.16216^running, since Fri May 13 22:07:02 2016^cache.cpf^56772^57772^62972^ok^^Failover^" + status + "^/mgr/config")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(i.MirrorStatus).To(Equal(expected))
+		s := i.Mirror().Status
+		Expect(s.IsPrimary()).To(Equal(primary), "primary")
+		Expect(s.IsConnected()).To(Equal(connected), "connected")
+		Expect(s.IsInTrouble()).To(Equal(inTrouble), "in trouble")
+	},
+		Entry("is primary", "PRIMARY", isclib.MirrorStatusPrimary, true, false, false),
+		Entry("is backup", "Backup", isclib.MirrorStatusBackup, false, true, false),
+		Entry("is connected", "connected", isclib.MirrorStatusConnected, false, true, false),
+		Entry("is in trouble", "in trouble", isclib.MirrorStatusInTrouble, false, false, true),
+		Entry("is in trouble without spacing", "InTrouble", isclib.MirrorStatusInTrouble, false, false, true),
+		Entry("is not initialized", "Not Initialized", isclib.MirrorStatusNotInitialized, false, false, false),
+		Entry("is unknown", "Something Else", "Something Else", false, false, false),
+	)
+
+	Describe("MirrorSplitBrainCheck", func() {
+		member := func(name, memberType, status string) *isclib.Instance {
+			return &isclib.Instance{Name: name, MirrorMemberType: memberType, MirrorStatus: status}
+		}
+
+		It("Reports a healthy mirror", func() {
+			splitBrain, err := isclib.MirrorSplitBrainCheck(isclib.Instances{
+				member("IRISA", isclib.MirrorMemberTypeFailover, isclib.MirrorStatusPrimary),
+				member("IRISB", isclib.MirrorMemberTypeFailover, isclib.MirrorStatusBackup),
+				member("IRISDR", isclib.MirrorMemberTypeDisasterRecovery, isclib.MirrorStatusConnected),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(splitBrain).To(BeFalse())
+		})
+
+		It("Reports a mirror without a primary", func() {
+			splitBrain, err := isclib.MirrorSplitBrainCheck(isclib.Instances{
+				member("IRISA", isclib.MirrorMemberTypeFailover, isclib.MirrorStatusInTrouble),
+				member("IRISB", isclib.MirrorMemberTypeFailover, isclib.MirrorStatusTransition),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(splitBrain).To(BeFalse())
+		})
+
+		It("Reports when more than one member claims to be the primary", func() {
+			splitBrain, err := isclib.MirrorSplitBrainCheck(isclib.Instances{
+				member("IRISA", isclib.MirrorMemberTypeFailover, isclib.MirrorStatusPrimary),
+				member("IRISB", isclib.MirrorMemberTypeFailover, "primary"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(splitBrain).To(BeTrue())
+		})
+
+		It("Returns ErrNotMirrored when a member is not mirrored", func() {
+			_, err := isclib.MirrorSplitBrainCheck(isclib.Instances{
+				member("IRISA", isclib.MirrorMemberTypeFailover, isclib.MirrorStatusPrimary),
+				member("IRISB", "", ""),
+			})
+			Expect(err).To(MatchError(isclib.ErrNotMirrored))
+			Expect(err).To(MatchError(ContainSubstring("IRISB")))
+		})
+	})
+
+	Describe("MirrorName", func() {
+		var instance *isclib.Instance
+
+		writeCPF := func(cpf string) {
+			Expect(os.WriteFile(filepath.Join(instance.DataDirectory, "iris.cpf"), []byte(cpf), 0644)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			instance = &isclib.Instance{Name: "INSTTEST", Product: isclib.Iris, DataDirectory: GinkgoT().TempDir(), CPFFileName: "iris.cpf"}
+		})
+
+		It("Reads the mirror name from the CPF", func() {
+			writeCPF(`[MirrorMember]
+AsyncMemberType=0
+SystemName=IRISA
+
+[Mirrors]
+PRODMIRROR=Name=PRODMIRROR
+`)
+			Expect(instance.MirrorName()).To(Equal("PRODMIRROR"))
+		})
+
+		It("Returns ErrNotMirrored when no mirror is configured", func() {
+			writeCPF(`[MirrorMember]
+SystemName=
+
+[Mirrors]
+`)
+			_, err := instance.MirrorName()
+			Expect(err).To(MatchError(isclib.ErrNotMirrored))
+		})
+
+		It("Returns an error when the CPF cannot be read", func() {
+			_, err := instance.MirrorName()
+			Expect(err).To(MatchError(os.ErrNotExist))
+		})
+	})
+})
@@ -0,0 +1,95 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Every configured database is opened to check whether it is currently mounted
+	mountedDatabasesCode = `MAIN
+ set rs=##class(%ResultSet).%New("Config.Databases:List")
+ set sc=rs.Execute("*")
+ if 'sc write "ERROR:",$system.Status.GetErrorText(sc),! quit
+ while rs.Next() {
+ set db=##class(SYS.Database).%OpenId(rs.Get("Directory"))
+ if $isobject(db),db.Mounted write "MOUNTED:",db.Directory,!
+ }
+ quit
+
+`
+)
+
+var (
+	mountedDatabaseRegexp = regexp.MustCompile(`(?m)^MOUNTED:(.*?)\r?$`)
+)
+
+// MountedDatabases will query the running instance for the directories of its configured databases which are
+// currently mounted.
+// It returns the database directories and any error encountered.
+func (i *Instance) MountedDatabases() ([]string, error) {
+	out, err := i.ExecuteString(systemNamespace, mountedDatabasesCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configOutputError(out); err != nil {
+		return nil, fmt.Errorf("unable to determine mounted databases: %w", err)
+	}
+
+	directories := make([]string, 0)
+	for _, m := range mountedDatabaseRegexp.FindAllStringSubmatch(out, -1) {
+		directories = append(directories, strings.TrimSpace(m[1]))
+	}
+
+	return directories, nil
+}
+
+// WaitForDatabaseMounted waits for the database in the provided directory to be mounted (see MountedDatabases),
+// checking every interval until the context is done.  Failures to query the instance (e.g. while it is still starting)
+// are ignored.
+// It returns the context's error if the database was not mounted before the context was done.
+func (i *Instance) WaitForDatabaseMounted(ctx context.Context, directory string, interval time.Duration) error {
+	directory = filepath.Clean(directory)
+	wlog := log.WithFields(log.Fields{"instance": i.Name, "directory": directory})
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+			mounted, err := i.MountedDatabases()
+			if err != nil {
+				wlog.WithError(err).Debug("Unable to determine mounted databases")
+				continue
+			}
+
+			for _, m := range mounted {
+				if filepath.Clean(m) == directory {
+					return nil
+				}
+			}
+		}
+	}
+}
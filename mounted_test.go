@@ -0,0 +1,79 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("Mounted databases", func() {
+	var instance *Instance
+
+	// The fake session fails to run the code for the first failures executions and then writes the provided output
+	writeSession := func(failures int, output string) {
+		count := filepath.Join(GinkgoT().TempDir(), "count")
+		instance.SessionPath = WriteFakeSession(FakeSession{Main: fmt.Sprintf(`
+    count=$(cat %[1]s 2>/dev/null || echo 0)
+    echo $((count + 1)) > %[1]s
+    [ "$count" -lt %[2]d ] && exit 1
+    printf '%[3]s'`, count, failures, output)})
+	}
+
+	BeforeEach(func() {
+		SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+		instance = &Instance{Name: "INSTTEST"}
+	})
+	AfterEach(func() {
+		SetExecuteTemporaryDirectory("")
+	})
+
+	Describe("MountedDatabases", func() {
+		It("Returns the mounted database directories", func() {
+			writeSession(0, `MOUNTED:/usr/irissys/mgr/\nMOUNTED:/data/app/\n`)
+			Expect(instance.MountedDatabases()).To(Equal([]string{"/usr/irissys/mgr/", "/data/app/"}))
+		})
+
+		It("Returns an error reported by the code", func() {
+			writeSession(0, `ERROR:Access denied\n`)
+			_, err := instance.MountedDatabases()
+			Expect(err).To(MatchError(ContainSubstring("Access denied")))
+		})
+	})
+
+	Describe("WaitForDatabaseMounted", func() {
+		It("Waits until the database is mounted", func() {
+			writeSession(2, `MOUNTED:/data/app/\n`)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			Expect(instance.WaitForDatabaseMounted(ctx, "/data/app", 10*time.Millisecond)).To(Succeed())
+		})
+
+		It("Returns the context error when the database is never mounted", func() {
+			writeSession(0, `MOUNTED:/usr/irissys/mgr/\n`)
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			Expect(instance.WaitForDatabaseMounted(ctx, "/data/app/", 10*time.Millisecond)).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+})
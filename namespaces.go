@@ -0,0 +1,66 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// ListAll only includes the namespaces defined in this instance, not those of remote systems
+	namespacesCode = `MAIN
+ do ##class(%SYS.Namespace).ListAll(.list)
+ set ns="" for { set ns=$order(list(ns)) quit:ns=""  write "NAMESPACE:",ns,! }
+ quit
+
+`
+)
+
+var (
+	namespaceRegexp = regexp.MustCompile(`(?m)^NAMESPACE:(.*?)\r?$`)
+)
+
+// Namespaces will query the running instance for the namespaces defined in it.  Unlike NamespaceMappings this includes
+// namespaces which have not yet been written to the CPF.  %ALL is never included as it is not a real namespace.  If
+// skipSystem is true, the percent namespaces (e.g. %SYS) and the ISC library namespaces are also skipped.
+// It returns the sorted namespace names and any error encountered.
+func (i *Instance) Namespaces(skipSystem bool) ([]string, error) {
+	out, err := i.ExecuteString(systemNamespace, namespacesCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configOutputError(out); err != nil {
+		return nil, fmt.Errorf("unable to list namespaces: %w", err)
+	}
+
+	namespaces := make([]string, 0)
+	for _, m := range namespaceRegexp.FindAllStringSubmatch(out, -1) {
+		namespace := strings.TrimSpace(m[1])
+		// remote namespaces are reported as ^system^directory
+		if namespace == "" || strings.Contains(namespace, "^") || namespace == "%ALL" || (skipSystem && isSystemNamespace(namespace)) {
+			continue
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	return namespaces, nil
+}
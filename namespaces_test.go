@@ -0,0 +1,54 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("Namespaces", func() {
+	// The fake session records the namespace it was run in, imports the code and writes the namespaces when it is run
+	var (
+		instance  *Instance
+		namespace string
+	)
+
+	BeforeEach(func() {
+		SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+		namespace = filepath.Join(GinkgoT().TempDir(), "namespace")
+		instance = &Instance{Name: "INSTTEST", SessionPath: WriteFakeSession(FakeSession{
+			Main: `echo "$3" > ` + namespace + `; printf 'NAMESPACE:%s\n' USER %SYS %ALL ENSLIB APP`,
+		})}
+	})
+	AfterEach(func() {
+		SetExecuteTemporaryDirectory("")
+	})
+
+	It("Lists the namespaces from %SYS", func() {
+		Expect(instance.Namespaces(false)).To(Equal([]string{"%SYS", "APP", "ENSLIB", "USER"}))
+		Expect(os.ReadFile(namespace)).To(Equal([]byte("%SYS\n")))
+	})
+
+	It("Skips the system namespaces", func() {
+		Expect(instance.Namespaces(true)).To(Equal([]string{"APP", "USER"}))
+	})
+})
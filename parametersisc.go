@@ -18,18 +18,33 @@ package isclib
 
 import (
 	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
 const (
-	parameterLinePattern = `^\s*(?:([^.]+)\.)?(.+):\s*(.*)$`
+	// The key ends at the first colon so values may contain colons (e.g. URLs and ports)
+	parameterLinePattern = `^\s*(?:([^.:]+)\.)?([^:]+?):\s*(.*)$`
+	// A line ending with a backslash separated from the value by whitespace is continued on the following line, a
+	// backslash directly following the value (e.g. C:\InterSystems\IRIS\) is part of the value
+	parameterContinuationPattern = `\s\\$`
 )
 
 var (
-	parameterLineRegexp = regexp.MustCompile(parameterLinePattern)
+	parameterLineRegexp         = regexp.MustCompile(parameterLinePattern)
+	parameterContinuationRegexp = regexp.MustCompile(parameterContinuationPattern)
+
+	// ErrParameterNotFound is an error signifying that the parameters ISC file does not contain a single value for a key
+	ErrParameterNotFound = errors.New("parameter not found")
+	// ErrInvalidParameter is an error signifying that a parameter's value cannot be converted to the requested type
+	ErrInvalidParameter = errors.New("invalid parameter value")
 )
 
 // ParametersISC represents the contents of the parameters ISC file
@@ -48,16 +63,36 @@ type ParametersISCEntry struct {
 
 	// The values for this entry
 	Values []string
+
+	// The line of the parameters ISC file the entry first appears on, 0 if it was not loaded from a file
+	Line int
 }
 
 // LoadParametersISC will load the parameters contained in the provided reader
+// The line each entry first appears on is recorded so the original order can be recovered (see OrderedKeys).
+// A value which is too long for a single line may be continued on the following line by ending the line with
+// whitespace and a backslash.  The continuation line is appended to the value (which keeps the whitespace before the
+// backslash) with its leading whitespace removed.  A backslash directly following the value, as at the end of a Windows
+// directory, does not continue the line.
 // It returns the ParametersISC data structure and any error encountered
 func LoadParametersISC(r io.Reader) (ParametersISC, error) {
 	pi := make(ParametersISC)
 
 	scanner := bufio.NewScanner(r)
+	line := 0
 	for scanner.Scan() {
+		line++
+		start := line
 		t := scanner.Text()
+		for parameterContinuationRegexp.MatchString(t) {
+			t = strings.TrimSuffix(t, `\`)
+			if !scanner.Scan() {
+				break
+			}
+			line++
+			t += strings.TrimLeft(scanner.Text(), " \t")
+		}
+
 		if strings.TrimSpace(t) == "" {
 			continue
 		}
@@ -78,6 +113,7 @@ func LoadParametersISC(r io.Reader) (ParametersISC, error) {
 				Group:  group,
 				Name:   name,
 				Values: make([]string, 0),
+				Line:   start,
 			}
 		}
 
@@ -153,6 +189,72 @@ func (pi ParametersISC) Value(identifiers ...string) string {
 	return ""
 }
 
+// ValueOr will, given a set of identifiers making up a parameter key (see Value), return the single value at that key.
+// Unlike Value, an empty value is distinguished from a missing one.
+// It returns the value if a single value exists for the key or def if it does not
+func (pi ParametersISC) ValueOr(def string, identifiers ...string) string {
+	values := pi.Values(identifiers...)
+	if len(values) == 1 {
+		return values[0]
+	}
+
+	return def
+}
+
+// Int will, given a set of identifiers making up a parameter key (see Value), return the single value at that key as
+// an integer.
+// It returns the integer and an error wrapping ErrParameterNotFound if a single value does not exist for the key or
+// ErrInvalidParameter if it is not an integer.
+func (pi ParametersISC) Int(identifiers ...string) (int, error) {
+	value, err := pi.requiredValue(identifiers...)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s is not an integer: %q", ErrInvalidParameter, parameterKey(identifiers), value)
+	}
+
+	return n, nil
+}
+
+// Bool will, given a set of identifiers making up a parameter key (see Value), return the single value at that key as
+// a boolean.  Values such as 0/1 and true/false are accepted (see strconv.ParseBool).
+// It returns the boolean and an error wrapping ErrParameterNotFound if a single value does not exist for the key or
+// ErrInvalidParameter if it is not a boolean.
+func (pi ParametersISC) Bool(identifiers ...string) (bool, error) {
+	value, err := pi.requiredValue(identifiers...)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("%w: %s is not a boolean: %q", ErrInvalidParameter, parameterKey(identifiers), value)
+	}
+
+	return b, nil
+}
+
+// requiredValue returns the single value at the key or an error if a single value does not exist for it
+func (pi ParametersISC) requiredValue(identifiers ...string) (string, error) {
+	values := pi.Values(identifiers...)
+	switch len(values) {
+	case 1:
+		return values[0], nil
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrParameterNotFound, parameterKey(identifiers))
+	default:
+		return "", fmt.Errorf("%w: %s has %d values", ErrParameterNotFound, parameterKey(identifiers), len(values))
+	}
+}
+
+// parameterKey returns the identifiers of a parameter as a group.name key for errors
+func parameterKey(identifiers []string) string {
+	return strings.Join(identifiers, ".")
+}
+
 // Key returns the full group.name key for this element
 func (pie ParametersISCEntry) Key() string {
 	if pie.Group == "" {
@@ -161,3 +263,115 @@ func (pie ParametersISCEntry) Key() string {
 
 	return pie.Group + "." + pie.Name
 }
+
+// MarshalJSON encodes the parameters as an object of groups, each an object of entry names and their values
+// (e.g. {"security_settings":{"iris_user":["irisusr"]}}).  Groups and names are sorted so the encoding is stable.
+func (pi ParametersISC) MarshalJSON() ([]byte, error) {
+	groups := make(map[string]map[string][]string, len(pi))
+	for group, entries := range pi {
+		values := make(map[string][]string, len(entries))
+		for name, e := range entries {
+			if e != nil {
+				values[name] = e.Values
+			}
+		}
+		groups[group] = values
+	}
+
+	// maps are encoded with sorted keys
+	return json.Marshal(groups)
+}
+
+// UnmarshalJSON decodes parameters encoded by MarshalJSON
+func (pi *ParametersISC) UnmarshalJSON(b []byte) error {
+	var groups map[string]map[string][]string
+	if err := json.Unmarshal(b, &groups); err != nil {
+		return err
+	}
+
+	params := make(ParametersISC, len(groups))
+	for group, entries := range groups {
+		params[group] = make(ParametersISCGroup, len(entries))
+		for name, values := range entries {
+			if values == nil {
+				values = make([]string, 0)
+			}
+			params[group][name] = &ParametersISCEntry{Group: group, Name: name, Values: values}
+		}
+	}
+
+	*pi = params
+	return nil
+}
+
+// OrderedKeys returns the full group.name keys of the parameters in the order they appear in the parameters ISC file
+// they were loaded from (see LoadParametersISC).  Entries which were not loaded from a file (e.g. added by the caller
+// or decoded from JSON) follow in key order so the result is always stable.
+func (pi ParametersISC) OrderedKeys() []string {
+	entries := pi.orderedEntries()
+	keys := make([]string, 0, len(entries))
+	for _, e := range entries {
+		keys = append(keys, e.key)
+	}
+
+	return keys
+}
+
+// orderedParameter is a parameter entry along with the key it is stored under
+type orderedParameter struct {
+	key   string
+	entry *ParametersISCEntry
+}
+
+// orderedEntries returns the entries of the parameters in file order (see OrderedKeys)
+func (pi ParametersISC) orderedEntries() []orderedParameter {
+	entries := make([]orderedParameter, 0)
+	for group, names := range pi {
+		for name, e := range names {
+			if e == nil {
+				continue
+			}
+
+			key := name
+			if group != "" {
+				key = group + "." + name
+			}
+			entries = append(entries, orderedParameter{key: key, entry: e})
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b orderedParameter) int {
+		switch {
+		case a.entry.Line > 0 && b.entry.Line > 0 && a.entry.Line != b.entry.Line:
+			return cmp.Compare(a.entry.Line, b.entry.Line)
+		case a.entry.Line > 0 && b.entry.Line == 0:
+			return -1
+		case a.entry.Line == 0 && b.entry.Line > 0:
+			return 1
+		default:
+			return strings.Compare(a.key, b.key)
+		}
+	})
+
+	return entries
+}
+
+// WriteTo writes the parameters to the writer in the parameters ISC file format (see LoadParametersISC) with a line per
+// value.  The parameters are written in the order of the file they were loaded from (see OrderedKeys) so the output
+// can be meaningfully compared with the original.
+// It returns the number of bytes written and any error encountered.
+func (pi ParametersISC) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var n int64
+	for _, p := range pi.orderedEntries() {
+		for _, value := range p.entry.Values {
+			written, err := fmt.Fprintf(bw, "%s: %s\n", p.entry.Key(), value)
+			n += int64(written)
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+
+	return n, bw.Flush()
+}
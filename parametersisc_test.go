@@ -18,6 +18,7 @@ package isclib_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -70,13 +71,54 @@ dup: dup3
 				Expect(err).NotTo(HaveOccurred())
 			})
 			It("Contains the appropriate values", func() {
-				Expect(pi[""]["ng"]).To(Equal(&isclib.ParametersISCEntry{Group: "", Name: "ng", Values: []string{"ngval"}}))
-				Expect(pi["g1"]["n1"]).To(Equal(&isclib.ParametersISCEntry{Group: "g1", Name: "n1", Values: []string{"g1n1val"}}))
-				Expect(pi["g1"]["n2"]).To(Equal(&isclib.ParametersISCEntry{Group: "g1", Name: "n2", Values: []string{"g1n2val"}}))
-				Expect(pi["g2"]["n1"]).To(Equal(&isclib.ParametersISCEntry{Group: "g2", Name: "n1", Values: []string{"g2n1val"}}))
-				Expect(pi["g2"]["n2"]).To(Equal(&isclib.ParametersISCEntry{Group: "g2", Name: "n2", Values: []string{""}}))
-				Expect(pi["g2"]["n3"]).To(Equal(&isclib.ParametersISCEntry{Group: "g2", Name: "n3", Values: []string{""}}))
-				Expect(pi[""]["dup"]).To(Equal(&isclib.ParametersISCEntry{Group: "", Name: "dup", Values: []string{"dup1", "dup2", "dup3"}}))
+				Expect(pi[""]["ng"]).To(Equal(&isclib.ParametersISCEntry{Group: "", Name: "ng", Values: []string{"ngval"}, Line: 3}))
+				Expect(pi["g1"]["n1"]).To(Equal(&isclib.ParametersISCEntry{Group: "g1", Name: "n1", Values: []string{"g1n1val"}, Line: 4}))
+				Expect(pi["g1"]["n2"]).To(Equal(&isclib.ParametersISCEntry{Group: "g1", Name: "n2", Values: []string{"g1n2val"}, Line: 5}))
+				Expect(pi["g2"]["n1"]).To(Equal(&isclib.ParametersISCEntry{Group: "g2", Name: "n1", Values: []string{"g2n1val"}, Line: 6}))
+				Expect(pi["g2"]["n2"]).To(Equal(&isclib.ParametersISCEntry{Group: "g2", Name: "n2", Values: []string{""}, Line: 7}))
+				Expect(pi["g2"]["n3"]).To(Equal(&isclib.ParametersISCEntry{Group: "g2", Name: "n3", Values: []string{""}, Line: 8}))
+				Expect(pi[""]["dup"]).To(Equal(&isclib.ParametersISCEntry{Group: "", Name: "dup", Values: []string{"dup1", "dup2", "dup3"}, Line: 9}))
+			})
+			It("Returns the keys in file order", func() {
+				Expect(pi.OrderedKeys()).To(Equal([]string{"ng", "g1.n1", "g1.n2", "g2.n1", "g2.n2", "g2.n3", "dup"}))
+			})
+		})
+
+		Context("Continued lines", func() {
+			r := bytes.NewBufferString("g1.long: first \\\n  second\ng1.next: value\n")
+			pi, err := isclib.LoadParametersISC(r)
+			It("Records the line the entry starts on", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pi["g1"]["long"].Line).To(Equal(1))
+				Expect(pi["g1"]["next"].Line).To(Equal(3))
+			})
+		})
+
+		Context("Security settings", func() {
+			r := bytes.NewBufferString(`install.dir: C:\InterSystems\IRIS\
+install.instance: IRIS
+security_settings.cache_user: irisusr
+security_settings.manager_user: irisowner
+security_settings.authentication: password
+security_settings.authentication: delegated
+security_settings.ssl_ciphers: TLS_AES_256_GCM_SHA384:TLS_AES_128_GCM_SHA256: \
+    TLS_CHACHA20_POLY1305_SHA256
+security_settings.dbencmode: 0
+`)
+			pi, err := isclib.LoadParametersISC(r)
+			It("Does not return an error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("Keeps a trailing backslash in a Windows directory", func() {
+				Expect(pi.Value("install.dir")).To(Equal(`C:\InterSystems\IRIS\`))
+				Expect(pi.Value("install.instance")).To(Equal("IRIS"))
+			})
+			It("Collects the values of a repeated setting", func() {
+				Expect(pi.Values("security_settings.authentication")).To(Equal([]string{"password", "delegated"}))
+			})
+			It("Joins a continued value", func() {
+				Expect(pi.Value("security_settings", "ssl_ciphers")).To(Equal("TLS_AES_256_GCM_SHA384:TLS_AES_128_GCM_SHA256: TLS_CHACHA20_POLY1305_SHA256"))
+				Expect(pi["security_settings"]["dbencmode"].Line).To(Equal(9))
 			})
 		})
 	})
@@ -104,6 +146,44 @@ dup: dup3
 		})
 	})
 
+	Context("Typed values", func() {
+		pi := make(isclib.ParametersISC)
+		pi[""] = make(isclib.ParametersISCGroup)
+		pi[""]["empty"] = &isclib.ParametersISCEntry{Group: "", Name: "empty", Values: []string{""}}
+		pi[""]["dup"] = &isclib.ParametersISCEntry{Group: "", Name: "dup", Values: []string{"1", "2"}}
+		pi["g1"] = make(isclib.ParametersISCGroup)
+		pi["g1"]["port"] = &isclib.ParametersISCEntry{Group: "g1", Name: "port", Values: []string{"1972"}}
+		pi["g1"]["enabled"] = &isclib.ParametersISCEntry{Group: "g1", Name: "enabled", Values: []string{"1"}}
+		pi["g1"]["name"] = &isclib.ParametersISCEntry{Group: "g1", Name: "name", Values: []string{"irisusr"}}
+
+		It("Looks up values with a default", func() {
+			Expect(pi.ValueOr("def", "g1.name")).To(Equal("irisusr"))
+			Expect(pi.ValueOr("def", "empty")).To(Equal(""))
+			Expect(pi.ValueOr("def", "missing")).To(Equal("def"))
+			Expect(pi.ValueOr("def", "dup")).To(Equal("def"))
+		})
+
+		It("Looks up integers", func() {
+			Expect(pi.Int("g1", "port")).To(Equal(1972))
+			_, err := pi.Int("g1", "missing")
+			Expect(err).To(MatchError(isclib.ErrParameterNotFound))
+			Expect(err).To(MatchError(ContainSubstring("g1.missing")))
+			_, err = pi.Int("dup")
+			Expect(err).To(MatchError(isclib.ErrParameterNotFound))
+			_, err = pi.Int("g1.name")
+			Expect(err).To(MatchError(isclib.ErrInvalidParameter))
+			Expect(err).To(MatchError(ContainSubstring(`"irisusr"`)))
+		})
+
+		It("Looks up booleans", func() {
+			Expect(pi.Bool("g1.enabled")).To(BeTrue())
+			_, err := pi.Bool("missing")
+			Expect(err).To(MatchError(isclib.ErrParameterNotFound))
+			_, err = pi.Bool("empty")
+			Expect(err).To(MatchError(isclib.ErrInvalidParameter))
+		})
+	})
+
 	Context("Values", func() {
 		pi := make(isclib.ParametersISC)
 		pi[""] = make(isclib.ParametersISCGroup)
@@ -132,4 +212,52 @@ dup: dup3
 			Expect(isclib.ParametersISCEntry{Group: "g1", Name: "n1", Values: []string{"g1n1val"}}.Key()).To(Equal("g1.n1"))
 		})
 	})
+
+	Context("Serialization", func() {
+		const parameters = "ng: ngval\ndup: dup1\ndup: dup2\nsecurity_settings.iris_user: irisusr\nsecurity_settings.iris_group: irisusr\n"
+		var pi isclib.ParametersISC
+
+		BeforeEach(func() {
+			var err error
+			pi, err = isclib.LoadParametersISC(bytes.NewBufferString(parameters))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Encodes the parameters as grouped JSON", func() {
+			b, err := json.Marshal(pi)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(`{"":{"dup":["dup1","dup2"],"ng":["ngval"]},"security_settings":{"iris_group":["irisusr"],"iris_user":["irisusr"]}}`))
+		})
+
+		It("Round trips through JSON", func() {
+			b, err := json.Marshal(pi)
+			Expect(err).NotTo(HaveOccurred())
+			var decoded isclib.ParametersISC
+			Expect(json.Unmarshal(b, &decoded)).To(Succeed())
+			Expect(decoded.Values("dup")).To(Equal(pi.Values("dup")))
+			Expect(decoded.Values("security_settings.iris_user")).To(Equal(pi.Values("security_settings.iris_user")))
+			// The original file order is not encoded so decoded parameters are in key order
+			Expect(decoded.OrderedKeys()).To(Equal([]string{"dup", "ng", "security_settings.iris_group", "security_settings.iris_user"}))
+		})
+
+		It("Writes the parameters file format", func() {
+			var buf bytes.Buffer
+			n, err := pi.WriteTo(&buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(BeEquivalentTo(buf.Len()))
+			Expect(buf.String()).To(Equal(parameters))
+
+			reloaded, err := isclib.LoadParametersISC(&buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reloaded).To(Equal(pi))
+		})
+
+		It("Writes entries added after loading in key order", func() {
+			pi["a"] = isclib.ParametersISCGroup{"added": &isclib.ParametersISCEntry{Group: "a", Name: "added", Values: []string{"x"}}}
+			var buf bytes.Buffer
+			_, err := pi.WriteTo(&buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal(parameters + "a.added: x\n"))
+		})
+	})
 })
@@ -0,0 +1,93 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	managementPortalPath = "/csp/sys/UtilHome.csp"
+	defaultPortalHost    = "localhost"
+)
+
+var (
+	// ErrWebServerDisabled is an error signifying that the instance does not run its private web server (e.g. IRIS
+	// 2023.2 and newer which rely on an external web server)
+	ErrWebServerDisabled = errors.New("the instance's web server is not enabled")
+)
+
+// ManagementPortalURL will build the URL of the instance's Management Portal served by its private web server from the
+// [Startup] section of the instance's CPF: the web server port (WebServerPort, falling back to the port reported by
+// qlist), bind address (WebServerName, localhost if not set) and CSP prefix (WebServerURLPrefix, used when several
+// instances share a web server).  The URL uses https if the web server port serves TLS (see SetWebServerTLS).
+// It returns the URL and any error encountered, including ErrWebServerDisabled if the instance has no web server port.
+func (i *Instance) ManagementPortalURL() (string, error) {
+	startup, err := i.cpfSection("Startup")
+	if err != nil {
+		return "", err
+	}
+
+	port := i.WebServerPort
+	if p, ok := startup["WebServerPort"]; ok && p != "" {
+		if port, err = strconv.Atoi(p); err != nil {
+			return "", fmt.Errorf("invalid web server port %q: %w", p, err)
+		}
+	}
+
+	if port <= 0 {
+		return "", fmt.Errorf("%w, instance: %s", ErrWebServerDisabled, i.Name)
+	}
+
+	host := strings.TrimSpace(startup["WebServerName"])
+	if host == "" {
+		host = defaultPortalHost
+	}
+
+	scheme := "http"
+	if i.webServerTLS {
+		scheme = "https"
+	}
+
+	u := url.URL{
+		Scheme: scheme,
+		Host:   net.JoinHostPort(host, strconv.Itoa(port)),
+		Path:   path.Join("/", strings.Trim(startup["WebServerURLPrefix"], "/"), managementPortalPath),
+	}
+
+	return u.String(), nil
+}
+
+// SetWebServerTLS will configure whether the instance's web server port serves TLS (e.g. it is fronted by a proxy
+// terminating TLS on that port).  The CPF does not record this so it cannot be determined from the instance.
+func (i *Instance) SetWebServerTLS(enabled bool) {
+	log.WithField("enabled", enabled).Debug("Configured web server TLS")
+	i.webServerTLS = enabled
+}
+
+// WebServerTLS returns whether the instance's web server port serves TLS (see SetWebServerTLS)
+func (i *Instance) WebServerTLS() bool {
+	return i.webServerTLS
+}
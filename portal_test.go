@@ -0,0 +1,67 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("ManagementPortalURL", func() {
+	var instance *Instance
+
+	writeStartup := func(startup string) {
+		Expect(os.WriteFile(instance.CPFFilePath(), []byte("[Startup]\n"+startup), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		instance = &Instance{Name: "INSTTEST", DataDirectory: GinkgoT().TempDir(), CPFFileName: "iris.cpf", WebServerPort: 52773}
+	})
+
+	DescribeTable("Building the URL",
+		func(startup, expected string) {
+			writeStartup(startup)
+			Expect(instance.ManagementPortalURL()).To(Equal(expected))
+		},
+		Entry("defaults", "DefaultPort=1972\n", "http://localhost:52773/csp/sys/UtilHome.csp"),
+		Entry("configured port and address", "WebServerPort=57772\nWebServerName=db.example.com\n", "http://db.example.com:57772/csp/sys/UtilHome.csp"),
+		Entry("CSP prefix", "WebServerURLPrefix=/insttest/\n", "http://localhost:52773/insttest/csp/sys/UtilHome.csp"),
+		Entry("IPv6 address", "WebServerName=::1\n", "http://[::1]:52773/csp/sys/UtilHome.csp"),
+	)
+
+	It("Uses https when the web server port serves TLS", func() {
+		writeStartup("WebServerPort=57773\nWebServerName=db.example.com\n")
+		Expect(instance.WebServerTLS()).To(BeFalse())
+		instance.SetWebServerTLS(true)
+		Expect(instance.WebServerTLS()).To(BeTrue())
+		Expect(instance.ManagementPortalURL()).To(Equal("https://db.example.com:57773/csp/sys/UtilHome.csp"))
+	})
+
+	It("Returns an error when the web server is disabled", func() {
+		writeStartup("WebServerPort=0\n")
+		_, err := instance.ManagementPortalURL()
+		Expect(err).To(MatchError(ErrWebServerDisabled))
+	})
+
+	It("Returns an error when the CPF cannot be read", func() {
+		_, err := instance.ManagementPortalURL()
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+})
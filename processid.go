@@ -0,0 +1,73 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	cachePIDFileName = "cache.pid"
+	irisPIDFileName  = "iris.pid"
+)
+
+var (
+	// ErrInstanceDown is returned when an operation requires a running instance
+	ErrInstanceDown = errors.New("instance is down")
+	// ErrInvalidPIDFile is returned when the instance's pid file does not contain a process ID
+	ErrInvalidPIDFile = errors.New("pid file does not contain a valid process ID")
+)
+
+// ProcessID will determine the process ID of the instance's control process from the pid file written to the
+// instance's mgr directory.
+// The pid file is named for the product, so each candidate is checked for existence starting with the name used by
+// the instance's product.
+// It returns the process ID and any error encountered, including ErrInstanceDown if the instance is down.
+func (i *Instance) ProcessID() (int, error) {
+	if i.Status.Down() {
+		return 0, fmt.Errorf("%w, instance: %s", ErrInstanceDown, i.Name)
+	}
+
+	candidates := []string{cachePIDFileName, irisPIDFileName}
+	if i.Product == Iris {
+		candidates = []string{irisPIDFileName, cachePIDFileName}
+	}
+
+	for _, candidate := range candidates {
+		p := filepath.Join(i.MgrDirectory(), candidate)
+		b, err := os.ReadFile(p)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+
+		pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+		if err != nil || pid <= 0 {
+			return 0, fmt.Errorf("%w, path: %s", ErrInvalidPIDFile, p)
+		}
+
+		return pid, nil
+	}
+
+	return 0, fmt.Errorf("pid file not found in %s: %w", i.MgrDirectory(), os.ErrNotExist)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("ProcessID", func() {
+	var (
+		instance *isclib.Instance
+		mgr      string
+	)
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		mgr = filepath.Join(dir, "mgr")
+		Expect(os.MkdirAll(mgr, 0755)).To(Succeed())
+		instance = &isclib.Instance{Name: "INSTTEST", Product: isclib.Iris, DataDirectory: dir, Status: isclib.InstanceStatusRunning}
+	})
+
+	It("Returns the process ID from the pid file", func() {
+		Expect(os.WriteFile(filepath.Join(mgr, "iris.pid"), []byte("4321\n"), 0644)).To(Succeed())
+		Expect(instance.ProcessID()).To(Equal(4321))
+	})
+
+	It("Falls back to the pid file name of the other product", func() {
+		Expect(os.WriteFile(filepath.Join(mgr, "cache.pid"), []byte("1234"), 0644)).To(Succeed())
+		Expect(instance.ProcessID()).To(Equal(1234))
+	})
+
+	It("Returns an error when the pid file is invalid", func() {
+		Expect(os.WriteFile(filepath.Join(mgr, "iris.pid"), []byte("garbage"), 0644)).To(Succeed())
+		_, err := instance.ProcessID()
+		Expect(err).To(MatchError(isclib.ErrInvalidPIDFile))
+	})
+
+	It("Returns an error when the pid file does not exist", func() {
+		_, err := instance.ProcessID()
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+
+	It("Returns an error when the instance is down", func() {
+		Expect(os.WriteFile(filepath.Join(mgr, "iris.pid"), []byte("4321"), 0644)).To(Succeed())
+		instance.Status = isclib.InstanceStatusDown
+		_, err := instance.ProcessID()
+		Expect(err).To(MatchError(isclib.ErrInstanceDown))
+	})
+})
@@ -16,6 +16,13 @@ limitations under the License.
 
 package isclib
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
 // Product represents a particular ISC product
 type Product uint
 
@@ -30,11 +37,89 @@ const (
 	None Product = 0
 )
 
+var (
+	// ErrUnknownProduct is an error signifying that a product is not one of the ISC products
+	ErrUnknownProduct = errors.New("unknown ISC product")
+)
+
+// String returns the name of the product (Cache, Ensemble or IRIS) as accepted by ParseProduct
+func (p Product) String() string {
+	switch p {
+	case Cache:
+		return "Cache"
+	case Ensemble:
+		return "Ensemble"
+	case Iris:
+		return "IRIS"
+	default:
+		return fmt.Sprintf("Product(%d)", uint(p))
+	}
+}
+
+// MarshalJSON encodes the product as its lower case name (cache, ensemble or iris).
+// It returns an error wrapping ErrUnknownProduct for a value which is not one of the products.
+func (p Product) MarshalJSON() ([]byte, error) {
+	switch p {
+	case Cache, Ensemble, Iris:
+		return json.Marshal(strings.ToLower(p.String()))
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownProduct, uint(p))
+	}
+}
+
+// UnmarshalJSON decodes a product encoded by MarshalJSON.  The name is case-insensitive and, so JSON written before
+// products were encoded by name can still be read, the numeric value of a product is also accepted.
+// It returns an error wrapping ErrUnknownProduct for a name or value which is not one of the products.
+func (p *Product) UnmarshalJSON(b []byte) error {
+	var n uint
+	if err := json.Unmarshal(b, &n); err == nil {
+		if product := Product(n); product == Cache || product == Ensemble || product == Iris {
+			*p = product
+			return nil
+		}
+		return fmt.Errorf("%w: %d", ErrUnknownProduct, n)
+	}
+
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		return err
+	}
+
+	for _, product := range []Product{Cache, Ensemble, Iris} {
+		if strings.EqualFold(name, product.String()) {
+			*p = product
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownProduct, name)
+}
+
+// Edition represents a particular edition of an ISC product (e.g. IRIS for Health)
+type Edition string
+
+const (
+	// EditionIRIS is the IRIS Data Platform edition of IRIS
+	EditionIRIS Edition = "IRIS"
+	// EditionIRISForHealth is the IRIS for Health edition of IRIS
+	EditionIRISForHealth Edition = "IRIS for Health"
+	// EditionHealthShare is the HealthShare edition of IRIS
+	EditionHealthShare Edition = "HealthShare"
+	// EditionIRISCommunity is the community edition of IRIS
+	EditionIRISCommunity Edition = "IRIS Community"
+	// EditionNone indicates that the product has no edition (Cache and Ensemble)
+	EditionNone Edition = ""
+)
+
 // ParseProduct parses a string representing a ISC product into a Product.
+// The editions of IRIS (see ParseEdition) are all Iris.
 // The default for unknown strings is Cache.
 func ParseProduct(product string) Product {
 	switch product {
 	default:
+		if ParseEdition(product) != EditionNone {
+			return Iris
+		}
 		return Cache
 	case "Cache":
 		return Cache
@@ -44,3 +129,21 @@ func ParseProduct(product string) Product {
 		return Iris
 	}
 }
+
+// ParseEdition parses a string representing a ISC product into the Edition of IRIS it is.
+// Case and spacing are ignored (e.g. "IRISHealth" and "IRIS for Health" are both EditionIRISForHealth).
+// The default for unknown strings and products other than IRIS is EditionNone.
+func ParseEdition(product string) Edition {
+	switch strings.ToLower(strings.Join(strings.Fields(product), "")) {
+	default:
+		return EditionNone
+	case "idp", "iris", "irisdataplatform":
+		return EditionIRIS
+	case "irishealth", "irisforhealth":
+		return EditionIRISForHealth
+	case "healthshare", "hshealthconnect", "healthconnect":
+		return EditionHealthShare
+	case "iriscommunity", "iriscommunityedition":
+		return EditionIRISCommunity
+	}
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package isclib_test
 
 import (
+	"encoding/json"
+	"fmt"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/ontariosystems/isclib/v2"
@@ -38,5 +41,83 @@ var _ = Describe("InstanceStatus", func() {
 			Expect(isclib.ParseProduct("IRIS")).To(Equal(isclib.Iris), "IRIS product")
 			Expect(isclib.ParseProduct("IDP")).To(Equal(isclib.Iris), "IRIS product")
 		})
+		It("Successfully parses the editions of IRIS as IRIS", func() {
+			Expect(isclib.ParseProduct("IRISHealth")).To(Equal(isclib.Iris), "IRIS for Health product")
+			Expect(isclib.ParseProduct("IRIS for Health")).To(Equal(isclib.Iris), "IRIS for Health product")
+			Expect(isclib.ParseProduct("HealthShare")).To(Equal(isclib.Iris), "HealthShare product")
+			Expect(isclib.ParseProduct("IRIS Community")).To(Equal(isclib.Iris), "IRIS Community product")
+		})
+	})
+
+	DescribeTable("ParseEdition", func(product string, expected isclib.Edition) {
+		Expect(isclib.ParseEdition(product)).To(Equal(expected))
+	},
+		Entry("IRIS", "IRIS", isclib.EditionIRIS),
+		Entry("IDP", "IDP", isclib.EditionIRIS),
+		Entry("IRISHealth", "IRISHealth", isclib.EditionIRISForHealth),
+		Entry("IRIS for Health", "IRIS for Health", isclib.EditionIRISForHealth),
+		Entry("HealthShare", "HealthShare", isclib.EditionHealthShare),
+		Entry("IRIS Community", "IRIS Community", isclib.EditionIRISCommunity),
+		Entry("case and spacing", "iris  FOR health", isclib.EditionIRISForHealth),
+		Entry("Cache", "Cache", isclib.EditionNone),
+		Entry("Ensemble", "Ensemble", isclib.EditionNone),
+		Entry("unknown", "NotAProduct", isclib.EditionNone),
+	)
+
+	DescribeTable("String", func(product isclib.Product, expected string) {
+		Expect(product.String()).To(Equal(expected))
+		Expect(fmt.Sprint(product)).To(Equal(expected))
+	},
+		Entry("Cache", isclib.Cache, "Cache"),
+		Entry("Ensemble", isclib.Ensemble, "Ensemble"),
+		Entry("IRIS", isclib.Iris, "IRIS"),
+		Entry("unknown", isclib.Product(7), "Product(7)"),
+	)
+
+	Context("JSON", func() {
+		It("Encodes products by name", func() {
+			Expect(json.Marshal(isclib.Cache)).To(BeEquivalentTo(`"cache"`))
+			Expect(json.Marshal(isclib.Ensemble)).To(BeEquivalentTo(`"ensemble"`))
+			Expect(json.Marshal(isclib.Iris)).To(BeEquivalentTo(`"iris"`))
+		})
+
+		It("Fails to encode an unknown product", func() {
+			_, err := json.Marshal(isclib.Product(7))
+			Expect(err).To(MatchError(isclib.ErrUnknownProduct))
+		})
+
+		It("Round trips an instance", func() {
+			b, err := json.Marshal(&isclib.Instance{Name: "IRIS", Product: isclib.Iris})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(ContainSubstring(`"product":"iris"`))
+			var decoded isclib.Instance
+			Expect(json.Unmarshal(b, &decoded)).To(Succeed())
+			Expect(decoded.Product).To(Equal(isclib.Iris))
+		})
+
+		DescribeTable("Decoding", func(encoded string, expected isclib.Product) {
+			var p isclib.Product
+			Expect(json.Unmarshal([]byte(encoded), &p)).To(Succeed())
+			Expect(p).To(Equal(expected))
+		},
+			Entry("a name", `"ensemble"`, isclib.Ensemble),
+			Entry("a name in any case", `"IRIS"`, isclib.Iris),
+			Entry("a numeric value", `2`, isclib.Iris),
+		)
+
+		It("Fails to decode an unknown product", func() {
+			var p isclib.Product
+			Expect(json.Unmarshal([]byte(`"oracle"`), &p)).To(MatchError(isclib.ErrUnknownProduct))
+			Expect(json.Unmarshal([]byte(`7`), &p)).To(MatchError(isclib.ErrUnknownProduct))
+		})
+	})
+
+	Context("UpdateFromQList", func() {
+		It("Reports the edition of the product", func() {
+			i := new(isclib.Instance)
+			Expect(i.UpdateFromQList("HEALTH^/usr/irissys/^2024.1.0.267.2^running, since Fri May 13 22:07:02 2016^iris.cpf^1972^52773^62972^ok^IRIS for Health")).To(Succeed())
+			Expect(i.Product).To(Equal(isclib.Iris))
+			Expect(i.Edition).To(Equal(isclib.EditionIRISForHealth))
+		})
 	})
 })
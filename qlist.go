@@ -17,7 +17,9 @@ limitations under the License.
 package isclib
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 	"syscall"
@@ -26,33 +28,109 @@ import (
 )
 
 // qlist returns the results of executing qlist for the specified instance.
-// If instanceName is "", it will return the results of an argumentless qlist (which contains all instances)
+// If instanceName is "", it will return the results of an argumentless qlist (which contains all instances) using the
+// preferred control command.  Otherwise each available control command is tried in turn, as on a host with both Caché
+// and IRIS installed each control command may only list the instances of its own product.
 // It returns a string containing the combined standard input and output of the qlist command and any error which occurred.
 // If procAttr is not nil, it uses it to switch to run qlist as a different user
 func qlist(instanceName string, procAttr *syscall.SysProcAttr) (string, error) {
 	// Example qlist output...
 	// DOCKER^/ensemble/instances/docker/^2015.2.2.805.0.16216^down, last used Fri May 13 18:12:33 2016^cache.cpf^56772^57772^62972^^
 	// DOCKER^/ensemble/instances/docker^2018.1.1.643.0^running, since Mon Jul 23 14:42:09 2018^iris.cpf^1972^57772^62972^ok^IRIS^^^/ensemble/instances/docker
-	qlist := ""
+	paths := controlCommandPaths()
+	if len(paths) == 0 {
+		return "", nil
+	}
+
+	if instanceName == "" {
+		return runQlist(paths[0], "", procAttr)
+	}
+
+	var errs []error
+	for _, path := range paths {
+		out, err := runQlist(path, instanceName, procAttr)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if _, ok := qlistLine(out, instanceName); ok {
+			return out, nil
+		}
+	}
+
+	// no control command lists the instance, it is only an error if a control command could not be asked
+	return "", errors.Join(errs...)
+}
+
+// qlists returns the results of executing an argumentless qlist with every available control command.
+// On a host with both Caché and IRIS installed each control command may only list the instances of its own product.
+// A control command which fails is skipped so the instances of the other product are still listed.
+// It returns the output of each qlist command and any error which occurred, which is only returned if every control
+// command failed.
+func qlists() ([]string, error) {
+	paths := controlCommandPaths()
+	outputs := make([]string, 0, len(paths))
+	var errs []error
+	for _, path := range paths {
+		out, err := runQlist(path, "", nil)
+		if err != nil {
+			log.WithError(err).WithField("command", path).Warn("Unable to list the instances of a control command, skipping it")
+			errs = append(errs, err)
+			continue
+		}
+		outputs = append(outputs, out)
+	}
+
+	if len(outputs) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return outputs, nil
+}
+
+// controlCommandPaths returns the paths of the available control commands, in order of preference
+func controlCommandPaths() []string {
+	commands := AvailableCommands()
+	paths := make([]string, 0, 2)
+	if commands.Has(IrisCommand) {
+		paths = append(paths, os.ExpandEnv(globalIrisPath))
+	}
+	if commands.Has(CControlCommand) {
+		paths = append(paths, os.ExpandEnv(globalCControlPath))
+	}
+
+	return paths
+}
+
+// qlistLine returns the line of a qlist's output which describes the named instance (case-insensitive) and whether
+// there was one
+func qlistLine(q, instanceName string) (string, bool) {
+	for _, line := range strings.Split(q, "\n") {
+		name, _, _ := strings.Cut(strings.TrimSpace(line), qlistDelimiter)
+		if name != "" && strings.EqualFold(name, instanceName) {
+			return strings.TrimSpace(line), true
+		}
+	}
+
+	return "", false
+}
+
+// runQlist executes qlist using the provided control command (see qlist)
+func runQlist(controlPath, instanceName string, procAttr *syscall.SysProcAttr) (string, error) {
 	args := []string{"qlist"}
 	if instanceName != "" {
 		args = append(args, instanceName)
 	}
 
-	var cmd *exec.Cmd
-	commands := AvailableCommands()
-	switch {
-	case commands.Has(IrisCommand):
-		cmd = exec.Command(globalIrisPath, args...)
-	case commands.Has(CControlCommand):
-		cmd = exec.Command(globalCControlPath, args...)
-	default:
-		return qlist, nil
-	}
+	ctx, cancel := commandContext()
+	defer cancel()
 
+	cmd := exec.CommandContext(ctx, controlPath, args...)
 	cmd.SysProcAttr = procAttr
+	cmd.Env = commandEnv()
 	out, err := cmd.CombinedOutput()
-	if err != nil {
+	if err = commandContextError(ctx, err); err != nil {
 		log.WithError(err).WithFields(log.Fields{"output": string(out), "command": cmd.Path, "args": cmd.Args}).Debug("Error running qlist")
 		return "", fmt.Errorf("error running qlist: %w", err)
 	}
@@ -0,0 +1,90 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"strings"
+)
+
+// Qualifiers builds an ISC import/compile qualifier string (e.g. /compile/keepsource).
+// Only the qualifiers which have been set are rendered, everything else is left at the ISC default.
+// The zero value is ready to use.
+type Qualifiers struct {
+	compileFlags string
+	names        []string
+	values       map[string]bool
+}
+
+// NewQualifiers returns an empty set of qualifiers
+func NewQualifiers() *Qualifiers {
+	return new(Qualifiers)
+}
+
+// Compile sets whether the imported source should be compiled (/compile or /nocompile)
+func (q *Qualifiers) Compile(on bool) *Qualifiers { return q.Set("compile", on) }
+
+// KeepSource sets whether the source should be kept after compilation (/keepsource or /nokeepsource)
+func (q *Qualifiers) KeepSource(on bool) *Qualifiers { return q.Set("keepsource", on) }
+
+// Expand sets whether the expanded source should be kept (/expand or /noexpand)
+func (q *Qualifiers) Expand(on bool) *Qualifiers { return q.Set("expand", on) }
+
+// MultiCompile sets whether compilation may use multiple processes (/multicompile or /nomulticompile)
+func (q *Qualifiers) MultiCompile(on bool) *Qualifiers { return q.Set("multicompile", on) }
+
+// Display sets whether the import should display progress information (/display or /nodisplay)
+func (q *Qualifiers) Display(on bool) *Qualifiers { return q.Set("display", on) }
+
+// CompileFlags sets the legacy compile flags (e.g. "ck") which are rendered ahead of the qualifiers
+func (q *Qualifiers) CompileFlags(flags string) *Qualifiers {
+	q.compileFlags = flags
+	return q
+}
+
+// Set sets an arbitrary boolean qualifier by name.  The name should not include the leading / or a "no" prefix.
+func (q *Qualifiers) Set(name string, on bool) *Qualifiers {
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+	if q.values == nil {
+		q.values = make(map[string]bool)
+	}
+
+	if _, ok := q.values[name]; !ok {
+		q.names = append(q.names, name)
+	}
+
+	q.values[name] = on
+	return q
+}
+
+// String renders the qualifiers in the order they were first set.  A nil Qualifiers renders as "".
+func (q *Qualifiers) String() string {
+	if q == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(q.compileFlags)
+	for _, name := range q.names {
+		sb.WriteString("/")
+		if !q.values[name] {
+			sb.WriteString("no")
+		}
+		sb.WriteString(name)
+	}
+
+	return sb.String()
+}
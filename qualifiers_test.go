@@ -0,0 +1,129 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("Qualifiers", func() {
+	It("Renders nothing when empty", func() {
+		Expect(isclib.NewQualifiers().String()).To(Equal(""))
+		Expect((&isclib.Qualifiers{}).String()).To(Equal(""))
+		Expect((*isclib.Qualifiers)(nil).String()).To(Equal(""))
+	})
+
+	It("Renders the default import qualifiers", func() {
+		q := isclib.NewQualifiers().Compile(true).KeepSource(true).Expand(true).MultiCompile(true)
+		Expect(q.String()).To(Equal(isclib.DefaultImportQualifiers))
+	})
+
+	It("Renders disabled qualifiers with a no prefix", func() {
+		q := isclib.NewQualifiers().Compile(true).KeepSource(false).Display(false)
+		Expect(q.String()).To(Equal("/compile/nokeepsource/nodisplay"))
+	})
+
+	It("Keeps the original position when a qualifier is changed", func() {
+		q := isclib.NewQualifiers().Compile(true).KeepSource(true).Compile(false)
+		Expect(q.String()).To(Equal("/nocompile/keepsource"))
+	})
+
+	It("Renders compile flags ahead of the qualifiers", func() {
+		q := isclib.NewQualifiers().CompileFlags("ck").Display(false)
+		Expect(q.String()).To(Equal("ck/nodisplay"))
+	})
+
+	It("Normalizes arbitrary qualifier names", func() {
+		q := isclib.NewQualifiers().Set("/CheckUptoDate", true)
+		Expect(q.String()).To(Equal("/checkuptodate"))
+	})
+})
+
+var _ = Describe("DefaultImportQualifiers", func() {
+	AfterEach(func() {
+		isclib.SetDefaultImportQualifiers("")
+	})
+
+	It("Starts with DefaultImportQualifiers", func() {
+		Expect(isclib.GetDefaultImportQualifiers()).To(Equal(isclib.DefaultImportQualifiers))
+	})
+
+	It("Can be overridden", func() {
+		isclib.SetDefaultImportQualifiers("/compile/nodisplay")
+		Expect(isclib.GetDefaultImportQualifiers()).To(Equal("/compile/nodisplay"))
+	})
+
+	It("Is restored by setting a blank value", func() {
+		isclib.SetDefaultImportQualifiers("/compile/nodisplay")
+		isclib.SetDefaultImportQualifiers(" ")
+		Expect(isclib.GetDefaultImportQualifiers()).To(Equal(isclib.DefaultImportQualifiers))
+	})
+})
+
+var _ = Describe("Typed qualifiers", func() {
+	var src string
+
+	BeforeEach(func() {
+		src = GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(src, "Person.cls"), nil, 0644)).To(Succeed())
+	})
+
+	It("Are accepted by NewImportDescriptionWithQualifiers", func() {
+		id, err := isclib.NewImportDescriptionWithQualifiers(filepath.Join(src, "*.cls"), isclib.NewQualifiers().Compile(true).Display(false))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id.Qualifiers).To(Equal("/compile/nodisplay"))
+	})
+
+	Context("ImportSourceWithQualifiers", func() {
+		var (
+			instance *isclib.Instance
+			calls    string
+		)
+
+		BeforeEach(func() {
+			dir := GinkgoT().TempDir()
+			calls = filepath.Join(dir, "calls")
+			script := filepath.Join(dir, "session")
+			Expect(os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" >> "+calls+"\necho 'Load finished successfully.'\n"), 0755)).To(Succeed())
+			instance = &isclib.Instance{Name: "INSTTEST", SessionPath: script}
+		})
+
+		command := func() string {
+			b, err := os.ReadFile(calls)
+			Expect(err).NotTo(HaveOccurred())
+			return strings.TrimSpace(string(b))
+		}
+
+		It("Imports with the provided qualifiers", func() {
+			_, err := instance.ImportSourceWithQualifiers("USER", filepath.Join(src, "*.cls"), isclib.NewQualifiers().Compile(true).KeepSource(false))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(command()).To(ContainSubstring(`"/compile/nokeepsource"`))
+		})
+
+		It("Imports with the default qualifiers when none are provided", func() {
+			_, err := instance.ImportSourceWithQualifiers("USER", filepath.Join(src, "*.cls"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(command()).To(ContainSubstring(`"` + isclib.GetDefaultImportQualifiers() + `"`))
+		})
+	})
+})
@@ -0,0 +1,107 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+var (
+	// ErrRetriesExhausted is an error signifying that an operation was still failing once all of its attempts were used
+	ErrRetriesExhausted = errors.New("retries exhausted")
+
+	retrySleep = time.Sleep
+)
+
+// RetryPolicy describes how an operation is retried (see Retry)
+type RetryPolicy struct {
+	// The maximum number of times the operation is attempted, values less than 1 attempt it once
+	Attempts int
+	// The wait before the first retry, doubled for each subsequent retry
+	Backoff time.Duration
+	// The maximum wait between retries, 0 does not limit the wait
+	MaxBackoff time.Duration
+	// The fraction (0 to 1) of each wait which is randomized so concurrent callers do not retry in lockstep (e.g. 0.2
+	// waits between 80% and 120% of the backoff)
+	Jitter float64
+}
+
+// stopRetrying is an error which is returned immediately rather than retried (see StopRetrying)
+type stopRetrying struct {
+	err error
+}
+
+func (s stopRetrying) Error() string { return s.err.Error() }
+func (s stopRetrying) Unwrap() error { return s.err }
+
+// StopRetrying wraps an error returned to Retry to signify that the operation failed in a way which will not be fixed
+// by retrying it.  Retry returns the wrapped error immediately.  StopRetrying(nil) returns nil.
+func StopRetrying(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return stopRetrying{err: err}
+}
+
+// Retry will call fn until it succeeds, returns an error wrapped with StopRetrying or has been attempted attempts times,
+// waiting backoff before the first retry and doubling the wait for each subsequent retry (see RetryPolicy).
+// It returns nil if fn succeeded, the error passed to StopRetrying or an error wrapping both ErrRetriesExhausted and
+// the last error returned by fn.
+func Retry(attempts int, backoff time.Duration, fn func() error) error {
+	return RetryPolicy{Attempts: attempts, Backoff: backoff}.Retry(fn)
+}
+
+// Retry will call fn according to the policy (see the Retry function).
+func (p RetryPolicy) Retry(fn func() error) error {
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		var stop stopRetrying
+		if errors.As(err, &stop) {
+			return stop.err
+		}
+
+		if attempt >= p.Attempts {
+			return fmt.Errorf("%w, attempts: %d, error: %w", ErrRetriesExhausted, attempt, err)
+		}
+
+		retrySleep(p.jitter(backoff))
+
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// jitter randomizes the wait by up to the policy's jitter fraction in either direction
+func (p RetryPolicy) jitter(d time.Duration) time.Duration {
+	if p.Jitter <= 0 || d <= 0 {
+		return d
+	}
+
+	j := min(p.Jitter, 1)
+	return time.Duration(float64(d) * (1 - j + 2*j*rand.Float64()))
+}
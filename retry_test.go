@@ -0,0 +1,176 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Retry", func() {
+	var (
+		sleeps []time.Duration
+		calls  int
+		errFn  = errors.New("Blam!")
+	)
+
+	// failing returns a function which fails the provided number of times before succeeding
+	failing := func(failures int) func() error {
+		return func() error {
+			calls++
+			if calls <= failures {
+				return errFn
+			}
+			return nil
+		}
+	}
+
+	BeforeEach(func() {
+		sleeps = nil
+		calls = 0
+		retrySleep = func(d time.Duration) {
+			sleeps = append(sleeps, d)
+		}
+	})
+	AfterEach(func() {
+		retrySleep = time.Sleep
+	})
+
+	It("Does not wait when the first attempt succeeds", func() {
+		Expect(Retry(3, time.Second, failing(0))).To(Succeed())
+		Expect(calls).To(Equal(1))
+		Expect(sleeps).To(BeEmpty())
+	})
+
+	It("Retries with a doubling backoff", func() {
+		Expect(Retry(4, time.Second, failing(3))).To(Succeed())
+		Expect(calls).To(Equal(4))
+		Expect(sleeps).To(Equal([]time.Duration{time.Second, 2 * time.Second, 4 * time.Second}))
+	})
+
+	It("Gives up once the attempts are exhausted", func() {
+		err := Retry(3, time.Second, failing(10))
+		Expect(err).To(MatchError(ErrRetriesExhausted))
+		Expect(err).To(MatchError(errFn))
+		Expect(calls).To(Equal(3))
+		Expect(sleeps).To(HaveLen(2))
+	})
+
+	It("Attempts at least once", func() {
+		Expect(Retry(0, time.Second, failing(10))).To(MatchError(ErrRetriesExhausted))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("Stops when told to", func() {
+		err := Retry(3, time.Second, func() error {
+			calls++
+			return StopRetrying(errFn)
+		})
+		Expect(err).To(Equal(errFn))
+		Expect(calls).To(Equal(1))
+		Expect(sleeps).To(BeEmpty())
+	})
+
+	It("Caps the backoff", func() {
+		policy := RetryPolicy{Attempts: 4, Backoff: time.Second, MaxBackoff: 3 * time.Second}
+		Expect(policy.Retry(failing(3))).To(Succeed())
+		Expect(sleeps).To(Equal([]time.Duration{time.Second, 2 * time.Second, 3 * time.Second}))
+	})
+
+	It("Randomizes the backoff within the jitter", func() {
+		policy := RetryPolicy{Attempts: 20, Backoff: time.Second, MaxBackoff: time.Second, Jitter: 0.25}
+		Expect(policy.Retry(failing(19))).To(Succeed())
+		Expect(sleeps).To(HaveLen(19))
+		for _, d := range sleeps {
+			Expect(d).To(BeNumerically(">=", 750*time.Millisecond))
+			Expect(d).To(BeNumerically("<=", 1250*time.Millisecond))
+		}
+		Expect(sleeps).To(ContainElement(Not(Equal(time.Second))))
+	})
+})
+
+var _ = Describe("ControlRetry", func() {
+	// The fake control command fails for the first failures invocations and then succeeds
+	const controlScript = `#!/bin/sh
+count=$(cat %[1]s 2>/dev/null || echo 0)
+echo $((count + 1)) > %[1]s
+if [ "$count" -lt %[2]d ]; then
+  echo "another control command is running"
+  exit 1
+fi
+echo "ok"
+`
+	var (
+		instance *Instance
+		sleeps   []time.Duration
+	)
+
+	writeControl := func(failures int) {
+		dir := GinkgoT().TempDir()
+		script := filepath.Join(dir, "control")
+		Expect(os.WriteFile(script, []byte(fmt.Sprintf(controlScript, filepath.Join(dir, "count"), failures)), 0755)).To(Succeed())
+		instance.ControlPath = script
+	}
+
+	BeforeEach(func() {
+		sleeps = nil
+		retrySleep = func(d time.Duration) {
+			sleeps = append(sleeps, d)
+		}
+		instance = &Instance{Name: "INSTTEST"}
+	})
+	AfterEach(func() {
+		retrySleep = time.Sleep
+	})
+
+	It("Does not retry without a policy", func() {
+		writeControl(1)
+		_, err := instance.RunControlCommand("stat")
+		Expect(err).To(HaveOccurred())
+		Expect(sleeps).To(BeEmpty())
+	})
+
+	It("Retries failing control commands according to the policy", func() {
+		instance.SetControlRetry(RetryPolicy{Attempts: 3, Backoff: time.Second})
+		writeControl(2)
+		Expect(instance.RunControlCommand("stat")).To(Equal("ok\n"))
+		Expect(sleeps).To(Equal([]time.Duration{time.Second, 2 * time.Second}))
+	})
+
+	It("Gives up once the attempts are exhausted", func() {
+		instance.SetControlRetry(RetryPolicy{Attempts: 2, Backoff: time.Second})
+		writeControl(5)
+		out, err := instance.RunControlCommand("stat")
+		Expect(err).To(MatchError(ErrRetriesExhausted))
+		Expect(out).To(Equal("another control command is running\n"))
+	})
+
+	It("Does not retry once cleared", func() {
+		instance.SetControlRetry(RetryPolicy{Attempts: 3, Backoff: time.Second})
+		instance.ClearControlRetry()
+		writeControl(1)
+		_, err := instance.RunControlCommand("stat")
+		Expect(err).To(HaveOccurred())
+		Expect(sleeps).To(BeEmpty())
+	})
+})
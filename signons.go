@@ -0,0 +1,352 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Switch 12 inhibits new sign-ons to the instance
+	inhibitSignOnsCode = `do INT^SWSET(12,1) write !,"SIGNONS:INHIBITED",!`
+	allowSignOnsCode   = `do INT^SWSET(12,0) write !,"SIGNONS:ALLOWED",!`
+	// Only the processes which could be terminated are counted, the system processes cannot.  The session running this
+	// code is not counted.
+	activeProcessesCode = `set n=0,rs=##class(%ResultSet).%New("%SYS.ProcessQuery:ListPids") do rs.Execute() ` +
+		`while rs.Next() { set pid=rs.Get("Pid") continue:pid=$job  set p=##class(%SYS.ProcessQuery).%OpenId(pid) ` +
+		`if $isobject(p),p.CanBeTerminated set n=n+1 } write !,"ACTIVE:",n,!`
+	// Each command is run in a try block so an error is reported rather than leaving the session waiting for input
+	terminalCommandFmtStr = `try { %s } catch ex { write !,"ERROR:",ex.DisplayString(),! }`
+	terminalHalt          = "halt"
+
+	// signOnInhibitedMessage is reported by a session which cannot sign on because sign-ons are inhibited
+	signOnInhibitedMessage = "Sign-on inhibited"
+)
+
+var (
+	// ErrSignOnsInhibited is an error signifying that a session could not sign on to the instance because sign-ons have
+	// been inhibited (see InhibitSignOns)
+	ErrSignOnsInhibited = errors.New("sign-ons to the instance are inhibited")
+
+	// The interval between checks of the active processes while draining, a variable for testing
+	drainPollInterval = time.Second
+
+	signOnsInhibitedRegexp = regexp.MustCompile(`^SIGNONS:INHIBITED\r?$`)
+	signOnsAllowedRegexp   = regexp.MustCompile(`^SIGNONS:ALLOWED\r?$`)
+	activeProcessesRegexp  = regexp.MustCompile(`^ACTIVE:(\d+)\r?$`)
+	terminalErrorRegexp    = regexp.MustCompile(`^ERROR:(.*?)\r?$`)
+)
+
+// DrainAndStop will gracefully stop an instance by inhibiting new sign-ons, waiting up to drainTimeout for the active
+// user processes to finish and then stopping the instance (see Stop).
+// A single session is signed on before sign-ons are inhibited and used for every step so the drain does not depend on
+// signing on to an instance which refuses new sign-ons.
+// The instance is stopped once the processes have drained or the drain timeout has elapsed, whichever is first.
+// If ctx is done while draining, sign-ons are allowed again and the context error is returned.  The instance is stopped
+// with ctx (see StopContext) so a hung stop is killed when ctx is done.
+// It returns any error encountered.
+func (i *Instance) DrainAndStop(ctx context.Context, drainTimeout time.Duration) error {
+	ilog := log.WithField("name", i.Name)
+	if !i.Status.Up() {
+		return i.stopContext(ctx)
+	}
+
+	s, err := i.openTerminalSession()
+	if err != nil {
+		return fmt.Errorf("error inhibiting sign-ons, error: %w", err)
+	}
+	defer func() {
+		if err := s.close(); err != nil {
+			ilog.WithError(err).Debug("Error closing drain session")
+		}
+	}()
+
+	if _, err := s.run(ctx, inhibitSignOnsCode, signOnsInhibitedRegexp); err != nil {
+		return fmt.Errorf("error inhibiting sign-ons, error: %w", err)
+	}
+
+	if err := waitForProcessesToDrain(ctx, s, drainTimeout); err != nil {
+		// the session is still signed on even if ctx was done while it was running a command
+		if _, aerr := s.run(context.WithoutCancel(ctx), allowSignOnsCode, signOnsAllowedRegexp); aerr != nil {
+			ilog.WithError(aerr).Error("Failed to allow sign-ons after canceled drain")
+		}
+		return err
+	}
+
+	if err := s.close(); err != nil {
+		ilog.WithError(err).Debug("Error closing drain session")
+	}
+
+	return i.stopContext(ctx)
+}
+
+// stopContext stops the instance (see StopContext), bounded by both ctx and the default command timeout
+func (i *Instance) stopContext(ctx context.Context) error {
+	sctx, cancel := commandContextWithParent(ctx)
+	defer cancel()
+	return i.StopContext(sctx)
+}
+
+// waitForProcessesToDrain waits for the active user processes to finish or the drain timeout to elapse.
+// It only returns an error if the provided context is done.
+func waitForProcessesToDrain(ctx context.Context, s *terminalSession, drainTimeout time.Duration) error {
+	ilog := log.WithField("name", s.instance)
+	dctx, cancel := context.WithTimeout(ctx, drainTimeout)
+	defer cancel()
+
+	for {
+		n, err := s.activeProcesses(dctx)
+		switch {
+		case dctx.Err() != nil:
+		case err != nil:
+			ilog.WithError(err).Debug("Unable to determine active processes")
+		case n <= 0:
+			ilog.Debug("Processes drained")
+			return nil
+		default:
+			ilog.WithField("processes", n).Debug("Waiting for processes to drain")
+		}
+
+		select {
+		case <-dctx.Done():
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			ilog.WithField("timeout", drainTimeout).Debug("Drain timeout elapsed")
+			return nil
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
+
+// InhibitSignOns will prevent new sign-ons to the instance without stopping it.
+// Existing sessions are unaffected.  Sign-ons remain inhibited until AllowSignOns is called or the instance is
+// restarted.  Every isclib operation which signs on (Execute, etc.) is refused while sign-ons are inhibited.
+// Allowing sign-ons again requires a session, so the session which inhibited them is kept signed on by the Instance
+// until AllowSignOns is called.  Use DrainAndStop to inhibit sign-ons while stopping.
+// It returns any error encountered.
+func (i *Instance) InhibitSignOns() error {
+	log.WithField("name", i.Name).Debug("Inhibiting sign-ons")
+	if i.signOnSession != nil {
+		if _, err := i.signOnSession.run(context.Background(), inhibitSignOnsCode, signOnsInhibitedRegexp); err == nil {
+			return nil
+		}
+		_ = i.signOnSession.close()
+		i.signOnSession = nil
+	}
+
+	s, err := i.openTerminalSession()
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.run(context.Background(), inhibitSignOnsCode, signOnsInhibitedRegexp); err != nil {
+		_ = s.close()
+		return err
+	}
+
+	i.signOnSession = s
+	return nil
+}
+
+// AllowSignOns will allow new sign-ons to an instance where they have been inhibited (see InhibitSignOns).
+// Sign-ons are allowed through the session kept by InhibitSignOns.  Without one (e.g. sign-ons were inhibited by
+// another Instance or the kept session has exited) a new session must sign on, which fails with an error wrapping
+// ErrSignOnsInhibited while sign-ons are inhibited.
+// It returns any error encountered.
+func (i *Instance) AllowSignOns() error {
+	ilog := log.WithField("name", i.Name)
+	ilog.Debug("Allowing sign-ons")
+	if s := i.signOnSession; s != nil {
+		i.signOnSession = nil
+		_, err := s.run(context.Background(), allowSignOnsCode, signOnsAllowedRegexp)
+		if cerr := s.close(); cerr != nil {
+			ilog.WithError(cerr).Debug("Error closing sign-on session")
+		}
+		if err == nil {
+			return nil
+		}
+		ilog.WithError(err).Debug("Unable to allow sign-ons through the kept session, signing on")
+	}
+
+	return i.runTerminalCommand(allowSignOnsCode, signOnsAllowedRegexp)
+}
+
+// runTerminalCommand runs a single command in a new terminal session (see openTerminalSession)
+func (i *Instance) runTerminalCommand(code string, result *regexp.Regexp) error {
+	s, err := i.openTerminalSession()
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.run(context.Background(), code, result); err != nil {
+		_ = s.close()
+		return err
+	}
+
+	return s.close()
+}
+
+// terminalSession is an interactive %SYS session which runs the ObjectScript commands written to its standard input.
+// Unlike Execute it signs on once, so it keeps working after sign-ons have been inhibited.
+type terminalSession struct {
+	instance string
+	stdin    io.WriteCloser
+	lines    chan string // the lines of output, closed once the session exits
+	done     chan error
+	cancel   context.CancelFunc
+	closed   bool
+}
+
+// openTerminalSession signs on to a new terminal session in the %SYS namespace
+func (i *Instance) openTerminalSession() (*terminalSession, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := i.SessionCommandContext(ctx, systemNamespace, "")
+	cmd.Stdin = nil
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	r, w := io.Pipe()
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	s := &terminalSession{instance: i.Name, stdin: stdin, lines: make(chan string), done: make(chan error, 1), cancel: cancel}
+	go func(cmd *exec.Cmd) {
+		err := cmd.Wait()
+		_ = w.Close()
+		s.done <- err
+	}(cmd)
+	go func() {
+		defer close(s.lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			s.lines <- scanner.Text()
+		}
+		// discard any output left once the scanner fails so the session is not blocked writing it
+		_, _ = io.Copy(io.Discard, r)
+	}()
+
+	if i.sessionCredentials != nil {
+		if _, err := io.WriteString(stdin, i.sessionCredentials.username+"\n"+i.sessionCredentials.password+"\n"); err != nil {
+			_ = s.close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// run runs the command in the session and waits for the line of output matching result.
+// It stops waiting when ctx is done, leaving the session signed on; output left from the abandoned command is skipped
+// by the next command.  The session is killed if the default command timeout elapses first (see
+// SetDefaultCommandTimeout).
+// It returns the submatches of the result and any error encountered, including an error wrapping ErrSignOnsInhibited
+// if the session could not sign on.
+func (s *terminalSession) run(ctx context.Context, code string, result *regexp.Regexp) ([]string, error) {
+	tctx, cancel := commandContext()
+	defer cancel()
+	stop := context.AfterFunc(tctx, s.cancel)
+	defer stop()
+
+	// a session which failed to sign on has already exited, its output explains why the command could not be written
+	_, werr := fmt.Fprintf(s.stdin, terminalCommandFmtStr+"\n", code)
+
+	var output strings.Builder
+	for {
+		var line string
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case l, ok := <-s.lines:
+			if !ok {
+				return nil, s.ended(tctx, output.String(), werr)
+			}
+			line = l
+		}
+
+		output.WriteString(line + "\n")
+		if m := result.FindStringSubmatch(line); m != nil {
+			return m, nil
+		}
+
+		if m := terminalErrorRegexp.FindStringSubmatch(line); m != nil {
+			return nil, fmt.Errorf("error running command: %s", m[1])
+		}
+	}
+}
+
+// ended returns the error explaining why the session exited before a command's result was written
+func (s *terminalSession) ended(ctx context.Context, output string, werr error) error {
+	if strings.Contains(output, signOnInhibitedMessage) {
+		return fmt.Errorf("%w, output: %s", ErrSignOnsInhibited, output)
+	}
+
+	if werr != nil {
+		return commandContextError(ctx, fmt.Errorf("%w, output: %s", werr, output))
+	}
+
+	return commandContextError(ctx, fmt.Errorf("session ended unexpectedly, output: %s", output))
+}
+
+// activeProcesses returns the number of user processes other than the session itself
+func (s *terminalSession) activeProcesses(ctx context.Context) (int, error) {
+	m, err := s.run(ctx, activeProcessesCode, activeProcessesRegexp)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(m[1])
+}
+
+// close halts the session and waits for it to exit.
+// It returns any error encountered.
+func (s *terminalSession) close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	defer s.cancel()
+	ctx, cancel := commandContext()
+	defer cancel()
+	stop := context.AfterFunc(ctx, s.cancel)
+	defer stop()
+
+	_, _ = io.WriteString(s.stdin, terminalHalt+"\n")
+	_ = s.stdin.Close()
+	for range s.lines {
+	}
+
+	return <-s.done
+}
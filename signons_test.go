@@ -0,0 +1,214 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SignOns", func() {
+	const (
+		runningqlist = "INSTTEST^/ensemble/instances/insttest/^2015.2.2.805.0.16216^running, since Fri May 13 22:07:02 2016^cache.cpf^56772^57772^62972^ok^"
+		downqlist    = "INSTTEST^/ensemble/instances/insttest/^2015.2.2.805.0.16216^down, last used Fri May 13 22:07:02 2016^cache.cpf^56772^57772^62972^ok^"
+	)
+	var (
+		instance            *Instance
+		origCSessionCommand string
+	)
+
+	BeforeEach(func() {
+		origCSessionCommand = CSessionPath()
+		SetCSessionPath(filepath.Join(GinkgoT().TempDir(), "missing-csession"))
+		SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+		getQlist = func(instanceName string, _ *syscall.SysProcAttr) (string, error) {
+			return downqlist, nil
+		}
+		instance = &Instance{Name: "INSTTEST"}
+	})
+	AfterEach(func() {
+		SetCSessionPath(origCSessionCommand)
+		SetExecuteTemporaryDirectory("")
+		getQlist = qlist
+	})
+
+	// The fake terminal session responds to the commands written to it, reporting one fewer active process each time
+	// it is asked and recording the commands run in the log.  Like an instance, it refuses new sessions while sign-ons
+	// are inhibited
+	writeTerminal := func(active int) string {
+		dir := GinkgoT().TempDir()
+		script := filepath.Join(dir, "csession")
+		Expect(os.WriteFile(script, []byte(fmt.Sprintf(`#!/bin/sh
+log=%q
+inhibited=%q
+n=%d
+if [ -f "$inhibited" ]; then echo 'Sign-on inhibited.'; exit 1; fi
+while read -r line; do
+  case "$line" in
+    halt) echo halt >> "$log"; exit 0 ;;
+    *SWSET\(12,1\)*) echo inhibit >> "$log"; touch "$inhibited"; printf '%%%%SYS>\nSIGNONS:INHIBITED\n' ;;
+    *SWSET\(12,0\)*) echo allow >> "$log"; rm -f "$inhibited"; printf '%%%%SYS>\nSIGNONS:ALLOWED\n' ;;
+    *ProcessQuery*) echo "count:$n" >> "$log"; printf '%%%%SYS>\nACTIVE:%%d\n' $n; [ $n -gt 0 ] && n=$((n-1)) ;;
+    *) echo "ERROR:<SYNTAX>" ;;
+  esac
+done
+`, filepath.Join(dir, "log"), filepath.Join(dir, "inhibited"), active)), 0755)).To(Succeed())
+		SetCSessionPath(script)
+		return filepath.Join(dir, "log")
+	}
+
+	readLog := func(log string) []string {
+		b, err := os.ReadFile(log)
+		Expect(err).NotTo(HaveOccurred())
+		return strings.Fields(string(b))
+	}
+
+	Describe("DrainAndStop", func() {
+		BeforeEach(func() {
+			origDrainPollInterval := drainPollInterval
+			drainPollInterval = time.Millisecond
+			DeferCleanup(func() { drainPollInterval = origDrainPollInterval })
+		})
+
+		Context("The instance is down", func() {
+			It("Does not attempt to drain", func() {
+				Expect(instance.UpdateFromQList(downqlist)).To(Succeed())
+				Expect(instance.DrainAndStop(context.Background(), time.Minute)).To(Succeed())
+			})
+		})
+		Context("The instance is running", func() {
+			BeforeEach(func() {
+				Expect(instance.UpdateFromQList(runningqlist)).To(Succeed())
+				// the fake control command stops the instance, after which qlist reports it as down
+				instance.ControlPath = filepath.Join(GinkgoT().TempDir(), "ccontrol")
+				Expect(os.WriteFile(instance.ControlPath, []byte("#!/bin/sh\nexit 0\n"), 0755)).To(Succeed())
+			})
+
+			It("Drains the processes through a single session and stops", func() {
+				log := writeTerminal(2)
+				Expect(instance.DrainAndStop(context.Background(), time.Minute)).To(Succeed())
+				Expect(readLog(log)).To(Equal([]string{"inhibit", "count:2", "count:1", "count:0", "halt"}))
+				Expect(instance.Status).To(Equal(InstanceStatusDown))
+			})
+
+			It("Stops once the drain timeout elapses", func() {
+				log := writeTerminal(1000000)
+				Expect(instance.DrainAndStop(context.Background(), 20*time.Millisecond)).To(Succeed())
+				entries := readLog(log)
+				Expect(entries[0]).To(Equal("inhibit"))
+				Expect(entries[len(entries)-1]).To(Equal("halt"))
+				Expect(entries).NotTo(ContainElement("allow"))
+				Expect(instance.Status).To(Equal(InstanceStatusDown))
+			})
+
+			It("Allows sign-ons again from the same session when canceled", func() {
+				log := writeTerminal(1000000)
+				ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+				defer cancel()
+				Expect(instance.DrainAndStop(ctx, time.Minute)).To(MatchError(context.DeadlineExceeded))
+				entries := readLog(log)
+				Expect(entries[0]).To(Equal("inhibit"))
+				Expect(entries[len(entries)-2:]).To(Equal([]string{"allow", "halt"}))
+			})
+
+			It("Stops waiting for a command which does not respond when canceled", func() {
+				dir := GinkgoT().TempDir()
+				log := filepath.Join(dir, "log")
+				script := filepath.Join(dir, "csession")
+				Expect(os.WriteFile(script, []byte(fmt.Sprintf(`#!/bin/sh
+log=%q
+while read -r line; do
+  case "$line" in
+    halt) echo halt >> "$log"; exit 0 ;;
+    *SWSET\(12,1\)*) echo inhibit >> "$log"; echo 'SIGNONS:INHIBITED' ;;
+    *SWSET\(12,0\)*) echo allow >> "$log"; echo 'SIGNONS:ALLOWED' ;;
+    *ProcessQuery*) echo count >> "$log" ;;
+  esac
+done
+`, log)), 0755)).To(Succeed())
+				SetCSessionPath(script)
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				defer cancel()
+				Expect(instance.DrainAndStop(ctx, time.Minute)).To(MatchError(context.DeadlineExceeded))
+				Expect(readLog(log)).To(Equal([]string{"inhibit", "count", "allow", "halt"}))
+				Expect(instance.Status).To(Equal(InstanceStatusRunning))
+			})
+
+			It("Kills a stop which does not finish when canceled", func() {
+				writeTerminal(0)
+				Expect(os.WriteFile(instance.ControlPath, []byte("#!/bin/sh\nexec sleep 10\n"), 0755)).To(Succeed())
+				ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+				defer cancel()
+				Expect(instance.DrainAndStop(ctx, time.Minute)).To(MatchError(context.DeadlineExceeded))
+			})
+
+			It("Returns an error when the session cannot be run", func() {
+				err := instance.DrainAndStop(context.Background(), time.Minute)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("error inhibiting sign-ons"))
+			})
+		})
+	})
+
+	Describe("InhibitSignOns and AllowSignOns", func() {
+		It("Return an error when the session cannot be run", func() {
+			Expect(instance.InhibitSignOns()).NotTo(Succeed())
+			Expect(instance.AllowSignOns()).NotTo(Succeed())
+		})
+
+		It("Allow sign-ons through the session which inhibited them", func() {
+			log := writeTerminal(0)
+			Expect(instance.InhibitSignOns()).To(Succeed())
+			Expect(instance.InhibitSignOns()).To(Succeed())
+			Expect(instance.AllowSignOns()).To(Succeed())
+			Expect(readLog(log)).To(Equal([]string{"inhibit", "inhibit", "allow", "halt"}))
+
+			Expect(instance.AllowSignOns()).To(Succeed())
+			Expect(readLog(log)).To(Equal([]string{"inhibit", "inhibit", "allow", "halt", "allow", "halt"}))
+		})
+
+		It("Cannot allow sign-ons inhibited by another instance", func() {
+			writeTerminal(0)
+			other := &Instance{Name: instance.Name}
+			Expect(other.InhibitSignOns()).To(Succeed())
+			Expect(instance.AllowSignOns()).To(MatchError(ErrSignOnsInhibited))
+			Expect(other.AllowSignOns()).To(Succeed())
+		})
+
+		It("Reports that sign-ons are inhibited when the session cannot sign on", func() {
+			script := filepath.Join(GinkgoT().TempDir(), "csession")
+			Expect(os.WriteFile(script, []byte("#!/bin/sh\necho 'Sign-on inhibited.'\nexit 1\n"), 0755)).To(Succeed())
+			SetCSessionPath(script)
+			Expect(instance.AllowSignOns()).To(MatchError(ErrSignOnsInhibited))
+		})
+
+		It("Reports an error from the command", func() {
+			script := filepath.Join(GinkgoT().TempDir(), "csession")
+			Expect(os.WriteFile(script, []byte("#!/bin/sh\nread -r line\necho 'ERROR:<PROTECT>'\nread -r line\n"), 0755)).To(Succeed())
+			SetCSessionPath(script)
+			Expect(instance.InhibitSignOns()).To(MatchError(ContainSubstring("<PROTECT>")))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// InstanceSpec is a serializable description of an instance's configuration capturing what is needed to provision a
+// matching instance elsewhere
+type InstanceSpec struct {
+	Name            string  `json:"name"`            // The name of the instance
+	Version         string  `json:"version"`         // The version of Caché/Ensemble/Iris
+	Product         Product `json:"product"`         // The product of the instance
+	Edition         Edition `json:"edition"`         // The edition of the product, empty for Cache/Ensemble
+	SuperServerPort int     `json:"superServerPort"` // The SuperServer port
+	WebServerPort   int     `json:"webServerPort"`   // The internal WebServer port
+
+	// The settings of the CPF keyed by section and then key
+	CPF map[string]map[string]string `json:"cpf"`
+	// The databases in the order they are configured in the CPF
+	Databases []Database `json:"databases"`
+	// The namespaces and their default databases sorted by namespace
+	Namespaces []NamespaceMapping `json:"namespaces"`
+
+	// These values are only available from a running instance and are empty otherwise
+	WebApplications []WebApp       `json:"webApplications,omitempty"` // The CSP/web applications
+	SecurityUsers   []SecurityUser `json:"securityUsers,omitempty"`   // The users of the security database
+}
+
+// ExportSpec will gather the configuration of the instance into a single InstanceSpec.
+// The CPF, databases and namespaces are read from the instance's files.  The web applications and security users can
+// only be queried from a running instance so they are only gathered when the instance is up (see Update).
+// It returns the spec and any error encountered.
+func (i *Instance) ExportSpec() (InstanceSpec, error) {
+	spec := InstanceSpec{
+		Name:            i.Name,
+		Version:         i.Version,
+		Product:         i.Product,
+		Edition:         i.Edition,
+		SuperServerPort: i.SuperServerPort,
+		WebServerPort:   i.WebServerPort,
+	}
+
+	cpf, err := i.LoadCPF()
+	if err != nil {
+		return InstanceSpec{}, err
+	}
+
+	spec.CPF = make(map[string]map[string]string, len(cpf.Sections))
+	for _, s := range cpf.Sections {
+		if len(s.Entries) > 0 {
+			spec.CPF[s.Name] = cpf.Section(s.Name)
+		}
+	}
+
+	if spec.Databases, err = i.Databases(); err != nil {
+		return InstanceSpec{}, fmt.Errorf("unable to read databases: %w", err)
+	}
+
+	mappings, err := i.NamespaceMappings()
+	if err != nil {
+		return InstanceSpec{}, fmt.Errorf("unable to read namespaces: %w", err)
+	}
+	spec.Namespaces = make([]NamespaceMapping, 0, len(mappings))
+	for _, m := range mappings {
+		spec.Namespaces = append(spec.Namespaces, m)
+	}
+	slices.SortFunc(spec.Namespaces, func(a, b NamespaceMapping) int {
+		return strings.Compare(a.Namespace, b.Namespace)
+	})
+
+	if i.Status.Up() {
+		if spec.WebApplications, err = i.WebApplications(); err != nil {
+			return InstanceSpec{}, err
+		}
+
+		if spec.SecurityUsers, err = i.SecurityUsers(); err != nil {
+			return InstanceSpec{}, err
+		}
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("ExportSpec", func() {
+	var (
+		instance *Instance
+		dir      string
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		cpf := "[ConfigFile]\nProduct=IRIS\nVersion=2024.1\n\n" +
+			"[Databases]\nUSER=" + filepath.Join(dir, "mgr", "user") + "/\nAPP=/data/app/\n\n" +
+			"[Namespaces]\nUSER=USER\nAPP=APP,USER\n\n" +
+			"[Startup]\nDefaultPort=1972\n\n[Actions]\n"
+		Expect(os.WriteFile(filepath.Join(dir, "iris.cpf"), []byte(cpf), 0644)).To(Succeed())
+		SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+		instance = &Instance{
+			Name:            "INSTTEST",
+			Version:         "2024.1.0.267.2",
+			Product:         Iris,
+			Edition:         EditionIRISForHealth,
+			SuperServerPort: 1972,
+			WebServerPort:   52773,
+			Status:          InstanceStatusDown,
+			DataDirectory:   dir,
+			CPFFileName:     "iris.cpf",
+		}
+	})
+	AfterEach(func() {
+		SetExecuteTemporaryDirectory("")
+	})
+
+	It("Gathers the configuration of a down instance from its files", func() {
+		spec, err := instance.ExportSpec()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Name).To(Equal("INSTTEST"))
+		Expect(spec.Version).To(Equal("2024.1.0.267.2"))
+		Expect(spec.Product).To(Equal(Iris))
+		Expect(spec.Edition).To(Equal(EditionIRISForHealth))
+		Expect(spec.SuperServerPort).To(Equal(1972))
+		Expect(spec.CPF).To(Equal(map[string]map[string]string{
+			"ConfigFile": {"Product": "IRIS", "Version": "2024.1"},
+			"Databases":  {"USER": filepath.Join(dir, "mgr", "user") + "/", "APP": "/data/app/"},
+			"Namespaces": {"USER": "USER", "APP": "APP,USER"},
+			"Startup":    {"DefaultPort": "1972"},
+		}))
+		Expect(spec.Databases).To(HaveLen(2))
+		Expect(spec.Databases[1]).To(Equal(Database{Name: "APP", Directory: "/data/app/", DatPath: "/data/app/IRIS.DAT"}))
+		Expect(spec.Namespaces).To(Equal([]NamespaceMapping{
+			{Namespace: "APP", GlobalsDatabase: "APP", RoutinesDatabase: "USER"},
+			{Namespace: "USER", GlobalsDatabase: "USER", RoutinesDatabase: "USER"},
+		}))
+		Expect(spec.WebApplications).To(BeNil())
+		Expect(spec.SecurityUsers).To(BeNil())
+	})
+
+	It("Queries a running instance for its web applications and users", func() {
+		// The fake session successfully imports the code and writes a web application and a user when it is run
+		instance.SessionPath = WriteFakeSession(FakeSession{Main: `printf 'WEBAPP:/csp/user\tUSER\t1\nUSER:_SYSTEM\t1\t%%All\n'`})
+		instance.Status = InstanceStatusRunning
+
+		spec, err := instance.ExportSpec()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.WebApplications).To(Equal([]WebApp{{Path: "/csp/user", Namespace: "USER", Enabled: true}}))
+		Expect(spec.SecurityUsers).To(Equal([]SecurityUser{{Name: "_SYSTEM", Enabled: true, Roles: []string{"%All"}}}))
+	})
+
+	It("Is serializable", func() {
+		spec, err := instance.ExportSpec()
+		Expect(err).NotTo(HaveOccurred())
+		b, err := json.Marshal(spec)
+		Expect(err).NotTo(HaveOccurred())
+		var decoded InstanceSpec
+		Expect(json.Unmarshal(b, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(spec))
+	})
+
+	It("Uses lowerCamel keys throughout", func() {
+		spec, err := instance.ExportSpec()
+		Expect(err).NotTo(HaveOccurred())
+		spec.WebApplications = []WebApp{{Path: "/csp/user", Namespace: "USER", Enabled: true}}
+		b, err := json.Marshal(spec)
+		Expect(err).NotTo(HaveOccurred())
+		var decoded map[string]any
+		Expect(json.Unmarshal(b, &decoded)).To(Succeed())
+		Expect(decoded["databases"]).To(ContainElement(HaveKeyWithValue("name", "USER")))
+		Expect(decoded["databases"]).To(ContainElement(HaveKey("underDataDirectory")))
+		Expect(decoded["namespaces"]).To(ContainElement(And(HaveKeyWithValue("namespace", "APP"), HaveKeyWithValue("globalsDatabase", "APP"))))
+		Expect(decoded["webApplications"]).To(ContainElement(HaveKeyWithValue("path", "/csp/user")))
+	})
+
+	It("Returns an error when the CPF cannot be read", func() {
+		instance.CPFFileName = "missing.cpf"
+		_, err := instance.ExportSpec()
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+})
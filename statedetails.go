@@ -0,0 +1,78 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	stateDetailsLogLines = 1000
+	// Console log entries at or above this severity (2 = severe, 3 = fatal) cause the instance to report a warn state
+	stateDetailsMinSeverity = 2
+	consoleLogTimeLayout    = "01/02/06-15:04:05"
+)
+
+var (
+	// e.g. 06/06/23-14:06:02:413 (1234) 2 [Generic.Event] Journal file switch failed
+	consoleLogEntryRegexp = regexp.MustCompile(`^(\d{2}/\d{2}/\d{2}-\d{2}:\d{2}:\d{2}):\d{3} \(\d+\) (\d) (.*)$`)
+)
+
+// StateDetails will determine the reasons behind a warn (or worse) state by scanning the end of the instance's console
+// log (see ConsoleLogPath) for the severe and fatal entries logged since the instance started.
+// It returns the messages of the entries (oldest first), or an empty slice if the state is ok, and any error
+// encountered.
+func (i *Instance) StateDetails() ([]string, error) {
+	details := make([]string, 0)
+	if i.State == "" || i.State == "ok" {
+		return details, nil
+	}
+
+	content, err := i.consoleLogTail(stateDetailsLogLines)
+	if err != nil {
+		return nil, err
+	}
+
+	since, err := i.RunningSince()
+	if err != nil {
+		since = time.Time{}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		m := consoleLogEntryRegexp.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+
+		if severity, _ := strconv.Atoi(m[2]); severity < stateDetailsMinSeverity {
+			continue
+		}
+
+		if logged, err := time.ParseInLocation(consoleLogTimeLayout, m[1], time.Local); err == nil && logged.Before(since) {
+			continue
+		}
+
+		details = append(details, m[3])
+	}
+
+	return details, scanner.Err()
+}
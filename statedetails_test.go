@@ -0,0 +1,70 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("StateDetails", func() {
+	const messages = `05/13/16-21:00:00:000 (100) 3 Fatal error from a previous run
+05/13/16-22:07:02:120 (200) 0 Startup of InterSystems IRIS
+05/13/16-22:08:00:001 (200) 1 Journal file is nearly full
+05/13/16-22:09:15:512 (300) 2 [Generic.Event] Journal file switch failed
+not a console log entry
+05/13/16-22:10:00:250 (300) 3 [SYSTEM MONITOR] DiskPercentFull Alert: 99
+`
+	var instance *isclib.Instance
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(dir, "mgr"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "mgr", "messages.log"), []byte(messages), 0644)).To(Succeed())
+		instance = &isclib.Instance{
+			Name:          "INSTTEST",
+			Product:       isclib.Iris,
+			DataDirectory: dir,
+			Status:        isclib.InstanceStatusRunning,
+			Activity:      "since Fri May 13 22:07:02 2016",
+			State:         "warn",
+		}
+	})
+
+	It("Returns the severe and fatal messages logged since the instance started", func() {
+		Expect(instance.StateDetails()).To(Equal([]string{
+			"[Generic.Event] Journal file switch failed",
+			"[SYSTEM MONITOR] DiskPercentFull Alert: 99",
+		}))
+	})
+
+	It("Returns no details when the state is ok", func() {
+		instance.State = "ok"
+		Expect(instance.StateDetails()).To(BeEmpty())
+	})
+
+	It("Returns an error when the console log cannot be found", func() {
+		instance.DataDirectory = GinkgoT().TempDir()
+		_, err := instance.StateDetails()
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+})
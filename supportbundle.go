@@ -0,0 +1,151 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	supportBundleLogLines = 1000
+)
+
+// SupportBundle will write a gzipped tar archive of the diagnostic information support teams typically request to the
+// provided io.Writer.  The archive contains the instance details (including the version), the qlist line, the CPF,
+// the tail of the console log, the database information and the stat output.
+// Collection is best effort, anything which cannot be collected is described in errors.txt within the archive.
+// It returns any error encountered writing the archive.
+func (i *Instance) SupportBundle(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	var problems []string
+
+	add := func(name string, content []byte, err error) error {
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", name, err))
+			return nil
+		}
+
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), ModTime: now}); err != nil {
+			return err
+		}
+
+		_, err = tw.Write(content)
+		return err
+	}
+
+	content, err := json.MarshalIndent(i, "", "  ")
+	if err := add("instance.json", content, err); err != nil {
+		return err
+	}
+
+	q, err := i.qlistLine()
+	if err := add("qlist.txt", []byte(q), err); err != nil {
+		return err
+	}
+
+	content, err = os.ReadFile(i.CPFFilePath())
+	if err := add(i.CPFFileName, content, err); err != nil {
+		return err
+	}
+
+	content, err = i.consoleLogTail(supportBundleLogLines)
+	if err := add("console.log", content, err); err != nil {
+		return err
+	}
+
+	dats, err := i.DatInfo()
+	if err == nil {
+		content, err = json.MarshalIndent(dats, "", "  ")
+	}
+	if err := add("databases.json", content, err); err != nil {
+		return err
+	}
+
+	stat, err := i.Stat()
+	if err := add("stat.txt", []byte(stat), err); err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		if err := add("errors.txt", []byte(strings.Join(problems, "\n")+"\n"), nil); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+func (i *Instance) qlistLine() (string, error) {
+	procAttr, err := i.managerSysProc()
+	if err != nil {
+		return "", err
+	}
+
+	return getQlist(i.Name, i.commandSysProcAttr(procAttr))
+}
+
+// consoleLogTail returns the last n lines of the instance's console log
+func (i *Instance) consoleLogTail(n int) ([]byte, error) {
+	p, err := i.ConsoleLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := make([]string, 0, n)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(lines) == n {
+			lines = lines[1:]
+		}
+		lines = append(lines, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", filepath.Base(p), err)
+	}
+
+	var b bytes.Buffer
+	for _, l := range lines {
+		b.WriteString(l)
+		b.WriteString("\n")
+	}
+
+	return b.Bytes(), nil
+}
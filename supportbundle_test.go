@@ -0,0 +1,112 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SupportBundle", func() {
+	const bundleqlist = "INSTTEST^/ensemble/instances/insttest/^2018.1.1.643.0^running, since Fri May 13 22:07:02 2016^iris.cpf^56772^57772^62972^ok^IRIS"
+	var (
+		instance *Instance
+		files    map[string]string
+	)
+
+	readBundle := func(b []byte) map[string]string {
+		gz, err := gzip.NewReader(bytes.NewReader(b))
+		Expect(err).NotTo(HaveOccurred())
+		tr := tar.NewReader(gz)
+		contents := make(map[string]string)
+		for {
+			h, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).NotTo(HaveOccurred())
+			c, err := io.ReadAll(tr)
+			Expect(err).NotTo(HaveOccurred())
+			contents[h.Name] = string(c)
+		}
+		return contents
+	}
+
+	BeforeEach(func() {
+		getQlist = func(instanceName string, _ *syscall.SysProcAttr) (string, error) {
+			return bundleqlist, nil
+		}
+		dir := GinkgoT().TempDir()
+		control := filepath.Join(dir, "control")
+		Expect(os.WriteFile(control, []byte("#!/bin/sh\necho \"$@\"\n"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(dir, "mgr"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "iris.cpf"), []byte("[Databases]\nUSER=/db/user/\n\n"), 0644)).To(Succeed())
+		var log strings.Builder
+		for n := 0; n < supportBundleLogLines+10; n++ {
+			fmt.Fprintf(&log, "line %d\n", n)
+		}
+		Expect(os.WriteFile(filepath.Join(dir, "mgr", "messages.log"), []byte(log.String()), 0644)).To(Succeed())
+		instance = &Instance{Name: "INSTTEST", DataDirectory: dir, CPFFileName: "iris.cpf", Product: Iris, ControlPath: control, Version: "2018.1.1.643.0"}
+	})
+	AfterEach(func() {
+		getQlist = qlist
+	})
+
+	Context("Everything can be collected", func() {
+		BeforeEach(func() {
+			var b bytes.Buffer
+			Expect(instance.SupportBundle(&b)).To(Succeed())
+			files = readBundle(b.Bytes())
+		})
+		It("Contains the diagnostic files", func() {
+			Expect(files).To(HaveKey("instance.json"))
+			Expect(files["instance.json"]).To(ContainSubstring(`"version": "2018.1.1.643.0"`))
+			Expect(files["qlist.txt"]).To(Equal(bundleqlist))
+			Expect(files["iris.cpf"]).To(Equal("[Databases]\nUSER=/db/user/\n\n"))
+			Expect(files["databases.json"]).To(ContainSubstring(`"Path": "/db/user/"`))
+			Expect(files["stat.txt"]).To(Equal("stat INSTTEST\n"))
+			Expect(files).NotTo(HaveKey("errors.txt"))
+		})
+		It("Contains only the tail of the console log", func() {
+			lines := strings.Split(strings.TrimSpace(files["console.log"]), "\n")
+			Expect(lines).To(HaveLen(supportBundleLogLines))
+			Expect(lines[0]).To(Equal("line 10"))
+			Expect(lines[len(lines)-1]).To(Equal(fmt.Sprintf("line %d", supportBundleLogLines+9)))
+		})
+	})
+
+	Context("Some information cannot be collected", func() {
+		It("Describes the problems", func() {
+			Expect(os.Remove(filepath.Join(instance.DataDirectory, "mgr", "messages.log"))).To(Succeed())
+			var b bytes.Buffer
+			Expect(instance.SupportBundle(&b)).To(Succeed())
+			files = readBundle(b.Bytes())
+			Expect(files).NotTo(HaveKey("console.log"))
+			Expect(files["errors.txt"]).To(ContainSubstring("console.log: console log not found"))
+		})
+	})
+})
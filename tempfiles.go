@@ -0,0 +1,138 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrTemporaryDirectoryInaccessible is an error signifying that the execution user cannot reach the temporary directory
+	ErrTemporaryDirectoryInaccessible = errors.New("temporary directory is not accessible")
+)
+
+// CleanupTempFiles removes temporary files left behind by ObjectScript executions which did not finish cleanly.
+// Files in the execute temporary directory starting with the execute temporary prefix and last modified more than
+// maxAge ago are removed.
+// It returns the number of files removed and any errors encountered.
+func CleanupTempFiles(maxAge time.Duration) (int, error) {
+	dir := temporaryDirectory()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	var errs []error
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.HasPrefix(entry.Name(), executeTempPrefix) {
+			continue
+		}
+
+		fi, err := entry.Info()
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if !fi.ModTime().Before(cutoff) {
+			continue
+		}
+
+		p := filepath.Join(dir, entry.Name())
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+
+		log.WithFields(log.Fields{"path": p, "modified": fi.ModTime()}).Debug("Removed stale temporary file")
+		removed++
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+// checkTemporaryDirectoryAccess ensures that every directory leading to (and including) the execute temporary directory
+// can be traversed by the user configured in procAttr.  Without this, the session is unable to read the import file.
+// Commands which switch users are run without supplementary groups, so only the user's primary group is considered.
+func checkTemporaryDirectoryAccess(procAttr *syscall.SysProcAttr) error {
+	uid, gid, ok := sysProcCredential(procAttr)
+	if !ok || uid == 0 {
+		return nil
+	}
+
+	dir, err := filepath.Abs(temporaryDirectory())
+	if err != nil {
+		return err
+	}
+
+	for d := dir; ; d = filepath.Dir(d) {
+		fi, err := os.Stat(d)
+		if err != nil {
+			return err
+		}
+
+		if !canTraverse(fi, uid, gid) {
+			return fmt.Errorf("%w: %s cannot be traversed by user %s (uid: %d, gid: %d, directory: %s)", ErrTemporaryDirectoryInaccessible, d, userName(uid), uid, gid, dir)
+		}
+
+		if d == filepath.Dir(d) {
+			return nil
+		}
+	}
+}
+
+func canTraverse(fi os.FileInfo, uid, gid uint32) bool {
+	owner, group, ok := fileOwnership(fi)
+	if !ok {
+		return true
+	}
+
+	perm := fi.Mode().Perm()
+	switch {
+	case owner == uid:
+		return perm&0100 != 0
+	case group == gid:
+		return perm&0010 != 0
+	default:
+		return perm&0001 != 0
+	}
+}
+
+// userName returns the name of the user with the uid, or the uid itself if the user cannot be looked up
+func userName(uid uint32) string {
+	id := strconv.FormatUint(uint64(uid), 10)
+	u, err := user.LookupId(id)
+	if err != nil {
+		return id
+	}
+
+	return u.Username
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("tempfiles", func() {
+	var origTemporaryDirectory string
+
+	BeforeEach(func() {
+		origTemporaryDirectory = ExecuteTemporaryDirectory()
+	})
+	AfterEach(func() {
+		SetExecuteTemporaryDirectory(origTemporaryDirectory)
+	})
+
+	Describe("checkTemporaryDirectoryAccess", func() {
+		const nobody = 65534
+		var (
+			dir      string
+			procAttr *syscall.SysProcAttr
+		)
+
+		BeforeEach(func() {
+			dir = filepath.Join(GinkgoT().TempDir(), "exec")
+			Expect(os.Mkdir(dir, 0755)).To(Succeed())
+			Expect(os.Chmod(filepath.Dir(dir), 0755)).To(Succeed())
+			SetExecuteTemporaryDirectory(dir)
+			procAttr = &syscall.SysProcAttr{Credential: &syscall.Credential{Uid: nobody, Gid: nobody}}
+		})
+
+		It("Succeeds when there is no execution user", func() {
+			Expect(checkTemporaryDirectoryAccess(nil)).To(Succeed())
+			Expect(checkTemporaryDirectoryAccess(&syscall.SysProcAttr{})).To(Succeed())
+		})
+
+		It("Succeeds when the directory can be traversed", func() {
+			Expect(checkTemporaryDirectoryAccess(procAttr)).To(Succeed())
+		})
+
+		It("Fails when the directory cannot be traversed", func() {
+			if os.Getuid() == nobody {
+				Skip("the test user owns the directory")
+			}
+			Expect(os.Chmod(dir, 0700)).To(Succeed())
+			err := checkTemporaryDirectoryAccess(procAttr)
+			Expect(err).To(MatchError(ErrTemporaryDirectoryInaccessible))
+			Expect(err.Error()).To(ContainSubstring(dir))
+			Expect(err.Error()).To(ContainSubstring("user " + userName(nobody)))
+		})
+
+		It("Only considers the primary group of the user", func() {
+			if os.Getuid() == nobody {
+				Skip("the test user owns the directory")
+			}
+			Expect(os.Chmod(dir, 0750)).To(Succeed())
+			fi, err := os.Stat(dir)
+			Expect(err).NotTo(HaveOccurred())
+			_, group, _ := fileOwnership(fi)
+
+			procAttr.Credential.Gid = group
+			Expect(checkTemporaryDirectoryAccess(procAttr)).To(Succeed())
+			procAttr.Credential.Gid = nobody
+			Expect(checkTemporaryDirectoryAccess(procAttr)).To(MatchError(ErrTemporaryDirectoryInaccessible))
+		})
+
+		It("Fails when a parent directory cannot be traversed", func() {
+			if os.Getuid() == nobody {
+				Skip("the test user owns the directory")
+			}
+			Expect(os.Chmod(filepath.Dir(dir), 0700)).To(Succeed())
+			Expect(checkTemporaryDirectoryAccess(procAttr)).To(MatchError(ErrTemporaryDirectoryInaccessible))
+		})
+	})
+
+	Describe("SetExecuteTempPrefix", func() {
+		AfterEach(func() {
+			Expect(SetExecuteTempPrefix(DefaultExecuteTempPrefix)).To(Succeed())
+		})
+
+		It("Defaults to ELEXEC", func() {
+			Expect(ExecuteTempPrefix()).To(Equal("ELEXEC"))
+		})
+
+		DescribeTable("Validates the prefix", func(prefix string, valid bool) {
+			err := SetExecuteTempPrefix(prefix)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ExecuteTempPrefix()).To(Equal(prefix))
+			} else {
+				Expect(err).To(MatchError(ErrInvalidTempPrefix))
+				Expect(ExecuteTempPrefix()).To(Equal(DefaultExecuteTempPrefix))
+			}
+		},
+			Entry("letters", "MYTOOL", true),
+			Entry("letters and digits", "Tool2", true),
+			Entry("percent prefix", "%TOOL", true),
+			Entry("blank", "", false),
+			Entry("leading digit", "2TOOL", false),
+			Entry("underscore", "MY_TOOL", false),
+			Entry("dot", "MY.TOOL", false),
+			Entry("path separator", "MY/TOOL", false),
+		)
+
+		It("Is used for the temporary file", func() {
+			SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+			Expect(SetExecuteTempPrefix("MYTOOL")).To(Succeed())
+			i := &Instance{}
+			path, err := i.genExecutorTmpFile(bytes.NewBufferString("MAIN\n quit\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.HasPrefix(filepath.Base(path), "MYTOOL")).To(BeTrue())
+		})
+	})
+
+	Describe("SetSessionIOTranslation", func() {
+		var i *Instance
+
+		BeforeEach(func() {
+			SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+			i = &Instance{}
+		})
+
+		It("Switches the translation before running the code", func() {
+			Expect(i.SetSessionIOTranslation("UTF8")).To(Succeed())
+			path, err := i.genExecutorTmpFile(bytes.NewBufferString("MAIN\n quit\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.ReadFile(path)).To(ContainSubstring("do ##class(%SYS.NLS.Device).SetIO(\"UTF8\")\n\t\tdo MAIN"))
+		})
+		It("Does not switch the translation by default", func() {
+			path, err := i.genExecutorTmpFile(bytes.NewBufferString("MAIN\n quit\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.ReadFile(path)).NotTo(ContainSubstring("SetIO"))
+			Expect(os.ReadFile(path)).To(ContainSubstring("try {\n\t\tdo MAIN"))
+		})
+		It("Rejects invalid table names", func() {
+			Expect(i.SetSessionIOTranslation(`UTF8")`)).To(MatchError(ErrInvalidIOTranslation))
+			Expect(i.SessionIOTranslation()).To(BeEmpty())
+		})
+		It("Clears the translation", func() {
+			Expect(i.SetSessionIOTranslation("Latin1")).To(Succeed())
+			Expect(i.SessionIOTranslation()).To(Equal("Latin1"))
+			Expect(i.SetSessionIOTranslation("")).To(Succeed())
+			Expect(i.SessionIOTranslation()).To(BeEmpty())
+		})
+	})
+
+	Describe("CleanupTempFiles", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			SetExecuteTemporaryDirectory(dir)
+			old := time.Now().Add(-2 * time.Hour)
+			for _, f := range []string{"ELEXEC1", "ELEXEC2", "OTHER1"} {
+				p := filepath.Join(dir, f)
+				Expect(os.WriteFile(p, []byte{}, 0644)).To(Succeed())
+				Expect(os.Chtimes(p, old, old)).To(Succeed())
+			}
+			Expect(os.WriteFile(filepath.Join(dir, "ELEXEC3"), []byte{}, 0644)).To(Succeed())
+			Expect(os.Mkdir(filepath.Join(dir, "ELEXECDIR"), 0755)).To(Succeed())
+		})
+
+		It("Removes only stale temporary files", func() {
+			Expect(CleanupTempFiles(time.Hour)).To(Equal(2))
+			entries, err := os.ReadDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			Expect(names).To(ConsistOf("ELEXEC3", "ELEXECDIR", "OTHER1"))
+		})
+
+		It("Returns an error when the directory does not exist", func() {
+			SetExecuteTemporaryDirectory(filepath.Join(dir, "missing"))
+			_, err := CleanupTempFiles(time.Hour)
+			Expect(err).To(MatchError(os.ErrNotExist))
+		})
+	})
+})
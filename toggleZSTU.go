@@ -17,99 +17,209 @@ limitations under the License.
 package isclib
 
 import (
-	"bufio"
+	"errors"
 	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
 
 	"github.com/spf13/afero"
 )
 
+// The CPF setting controlling whether the ^ZSTU startup routine is run
+const zstuKey = "ZSTU"
+
 // FS is a wrapper for the file system
 var FS = afero.NewOsFs()
 
 // ToggleZSTU ensures that the cpf file at the path provided has the ZSTU setting
 // set to true or false based on the provided boolean value.  It also returns the
 // original value for the ZSTU
+//
+// The updated file is written alongside the original and renamed over it so the
+// cpf file is never left partially written.  The permissions and ownership of the
+// original file are preserved.
 func ToggleZSTU(cpfFilePath string, onOrOff bool) (originalValue bool, err error) {
+	err = rewriteCPF(cpfFilePath, func(r io.Reader, w io.Writer) error {
+		cpf, err := ParseCPF(r)
+		if err != nil {
+			return err
+		}
+
+		originalValue = cpf.zstu()
+		cpf.setZSTU(onOrOff)
+		_, err = cpf.WriteTo(w)
+		return err
+	})
+
+	return originalValue, err
+}
+
+// rewriteCPF replaces the cpf file at the path provided with the output of rewrite.
+// The new contents are written alongside the original and renamed over it so the
+// file is never left partially written.  The permissions and ownership of the
+// original file are preserved; when the caller cannot give the new file the
+// original owner (e.g. it may only write the file through its group) the original
+// is overwritten in place instead.
+func rewriteCPF(cpfFilePath string, rewrite func(io.Reader, io.Writer) error) (err error) {
 	cpfFile, err := FS.Open(cpfFilePath)
 	if err != nil {
-		return originalValue, err
+		return err
 	}
+	defer cpfFile.Close()
 
-	tmpFile, err := afero.TempFile(FS, "", "cpftemp")
+	info, err := cpfFile.Stat()
 	if err != nil {
-		return originalValue, err
+		return err
 	}
 
-	originalValue, err = parseAndWriteCPF(cpfFile, tmpFile, onOrOff)
+	tmpFile, err := afero.TempFile(FS, filepath.Dir(cpfFilePath), "cpftemp")
 	if err != nil {
-		return originalValue, err
+		return err
 	}
+	defer func() {
+		if err != nil {
+			_ = FS.Remove(tmpFile.Name())
+		}
+	}()
 
-	if err = cpfFile.Close(); err != nil {
-		return originalValue, err
+	if err = rewrite(cpfFile, tmpFile); err != nil {
+		tmpFile.Close()
+		return err
 	}
 
 	if err = tmpFile.Close(); err != nil {
-		return originalValue, err
+		return err
+	}
+
+	if err = FS.Chmod(tmpFile.Name(), info.Mode().Perm()); err != nil {
+		return err
 	}
 
-	cpfFile, err = FS.Create(cpfFilePath)
+	preserved, err := preserveOwnership(tmpFile.Name(), info)
 	if err != nil {
-		return originalValue, err
+		return err
 	}
 
-	newCpfFile, err := FS.Open(tmpFile.Name())
+	if !preserved {
+		// the caller may write the file (e.g. through its group) without being able to give the replacement the
+		// original owner, so the original is overwritten in place instead of being replaced
+		return overwriteFile(tmpFile.Name(), cpfFilePath)
+	}
+
+	return FS.Rename(tmpFile.Name(), cpfFilePath)
+}
+
+// preserveOwnership gives the file at the path provided the ownership described by info when it differs.
+// It reports false, without an error, when the caller is not permitted to change the ownership.
+func preserveOwnership(path string, info os.FileInfo) (bool, error) {
+	uid, gid, ok := fileOwnership(info)
+	if !ok {
+		return true, nil
+	}
+
+	return changeOwnership(path, uid, gid)
+}
+
+// changeOwnership gives the file at the path provided the user and group provided when they differ.
+// It reports false, without an error, when the caller is not permitted to change the ownership.
+func changeOwnership(path string, uid, gid uint32) (bool, error) {
+	current, err := FS.Stat(path)
 	if err != nil {
-		return originalValue, err
+		return false, err
 	}
 
-	if _, err = io.Copy(cpfFile, newCpfFile); err != nil {
-		return originalValue, err
+	if curUID, curGID, ok := fileOwnership(current); ok && curUID == uid && curGID == gid {
+		return true, nil
 	}
 
-	if err := cpfFile.Close(); err != nil {
-		return originalValue, err
+	if err := FS.Chown(path, int(uid), int(gid)); err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return false, nil
+		}
+		return false, err
 	}
 
-	if err := newCpfFile.Close(); err != nil {
-		return originalValue, err
+	return true, nil
+}
+
+// overwriteFile copies the contents of the source file over the destination file, keeping the destination's
+// ownership and permissions, and removes the source
+func overwriteFile(src, dst string) error {
+	in, err := FS.Open(src)
+	if err != nil {
+		return err
 	}
+	defer in.Close()
 
-	if err := FS.Remove(tmpFile.Name()); err != nil {
-		return originalValue, err
+	out, err := FS.OpenFile(dst, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
 	}
 
-	return originalValue, nil
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return FS.Remove(src)
 }
 
-func parseAndWriteCPF(cpfFile io.Reader, tmpFile io.Writer, onOrOff bool) (originalValue bool, err error) {
-	scanner := bufio.NewScanner(cpfFile)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "ZSTU=1" || line == "ZSTU=0" {
-			if line == "ZSTU=1" {
-				originalValue = true
-			} else {
-				originalValue = false
-			}
-			if onOrOff {
-				if _, err = io.WriteString(tmpFile, "ZSTU=1\n"); err != nil {
-					return
-				}
-			} else {
-				if _, err = io.WriteString(tmpFile, "ZSTU=0\n"); err != nil {
-					return
-				}
-			}
-		} else {
-			if _, err = io.WriteString(tmpFile, scanner.Text()+"\n"); err != nil {
-				return
+// ToggleZSTU ensures that the CPF file used by this instance at startup (see CPFFilePath) has the ZSTU setting set to
+// true or false based on the provided boolean value.  It also returns the original value for the ZSTU.
+// See the ToggleZSTU function for how the file is updated.
+func (i *Instance) ToggleZSTU(onOrOff bool) (bool, error) {
+	return ToggleZSTU(i.CPFFilePath(), onOrOff)
+}
+
+// ReadZSTU reports whether the cpf file at the path provided has the ZSTU setting
+// set to true without modifying the file.  A file without a ZSTU setting is
+// reported as false, matching the original value returned by ToggleZSTU.
+func ReadZSTU(cpfFilePath string) (bool, error) {
+	cpfFile, err := FS.Open(cpfFilePath)
+	if err != nil {
+		return false, err
+	}
+	defer cpfFile.Close()
+
+	cpf, err := ParseCPF(cpfFile)
+	if err != nil {
+		return false, err
+	}
+
+	return cpf.zstu(), nil
+}
+
+// zstu reports whether the last ZSTU setting in the CPF is on
+func (c *CPF) zstu() bool {
+	on := false
+	for _, s := range c.Sections {
+		for _, e := range s.Entries {
+			if e.Key == zstuKey {
+				on = e.Value == "1"
 			}
 		}
 	}
-	if err = scanner.Err(); err != nil {
-		return
+
+	return on
+}
+
+// setZSTU changes every existing ZSTU setting in the CPF.  A missing setting is not added.
+func (c *CPF) setZSTU(onOrOff bool) {
+	value := "0"
+	if onOrOff {
+		value = "1"
 	}
 
-	return
+	for _, s := range c.Sections {
+		for n := range s.Entries {
+			if s.Entries[n].Key == zstuKey {
+				s.Entries[n].Value = value
+			}
+		}
+	}
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package isclib_test
 
 import (
+	"os"
 	"path/filepath"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -79,6 +80,124 @@ var _ = Describe("ToggleZSTU", func() {
 	})
 })
 
+var _ = Describe("Instance.ToggleZSTU", func() {
+	const (
+		dataDir = "/test/durable"
+		zstu0   = "some line that isn't ZSTU\nZSTU=0\n"
+		zstu1   = "some line that isn't ZSTU\nZSTU=1\n"
+	)
+	var instance *Instance
+
+	BeforeEach(func() {
+		FS = new(afero.MemMapFs)
+		Expect(FS.MkdirAll(dataDir, 0755)).To(Succeed())
+		Expect(afero.WriteFile(FS, filepath.Join(dataDir, "iris.cpf"), []byte(zstu0), 0640)).To(Succeed())
+		instance = &Instance{Directory: "/test/iris", DataDirectory: dataDir, CPFFileName: "iris.cpf"}
+	})
+
+	It("toggles the CPF in the data directory", func() {
+		Expect(instance.ToggleZSTU(true)).To(BeFalse())
+		Expect(afero.ReadFile(FS, instance.CPFFilePath())).To(WithTransform(toStr, Equal(zstu1)))
+	})
+
+	It("preserves the permissions of the CPF", func() {
+		Expect(instance.ToggleZSTU(true)).To(BeFalse())
+		info, err := FS.Stat(instance.CPFFilePath())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0640)))
+	})
+
+	It("does not leave temporary files behind", func() {
+		Expect(instance.ToggleZSTU(true)).To(BeFalse())
+		entries, err := afero.ReadDir(FS, dataDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("ReadZSTU", func() {
+	const (
+		path  = "/test/cache/cache.cpf"
+		zstu0 = "some line that isn't ZSTU\nanother line\nZSTU=0\nanother line\n"
+		zstu1 = "some line that isn't ZSTU\nanother line\nZSTU=1\nanother line\n"
+	)
+
+	BeforeEach(func() {
+		FS = new(afero.MemMapFs)
+		err := FS.MkdirAll(filepath.Dir(path), 0755)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("reads ZSTU=0 as false without modifying the file", func() {
+		Expect(afero.WriteFile(FS, path, []byte(zstu0), 0644)).To(Succeed())
+		Expect(ReadZSTU(path)).To(BeFalse())
+		Expect(afero.ReadFile(FS, path)).To(WithTransform(toStr, Equal(zstu0)))
+	})
+
+	It("reads ZSTU=1 as true without modifying the file", func() {
+		Expect(afero.WriteFile(FS, path, []byte(zstu1), 0644)).To(Succeed())
+		Expect(ReadZSTU(path)).To(BeTrue())
+		Expect(afero.ReadFile(FS, path)).To(WithTransform(toStr, Equal(zstu1)))
+	})
+
+	It("reads a missing ZSTU line as false", func() {
+		Expect(afero.WriteFile(FS, path, []byte("another line\n"), 0644)).To(Succeed())
+		Expect(ReadZSTU(path)).To(BeFalse())
+	})
+
+	It("returns an error when the file does not exist", func() {
+		_, err := ReadZSTU("/test/cache/missing.cpf")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
 func toStr(b []byte) string {
 	return string(b)
 }
+
+// chownRefusingFs is a file system on which the caller is not permitted to change the ownership of files
+type chownRefusingFs struct {
+	afero.Fs
+}
+
+func (fs chownRefusingFs) Chown(name string, uid, gid int) error {
+	return &os.PathError{Op: "chown", Path: name, Err: os.ErrPermission}
+}
+
+var _ = Describe("ToggleZSTU without permission to change ownership", func() {
+	var (
+		origFS afero.Fs
+		path   string
+	)
+
+	BeforeEach(func() {
+		if os.Geteuid() != 0 {
+			Skip("the CPF must be owned by another user, which requires root")
+		}
+
+		origFS = FS
+		FS = chownRefusingFs{afero.NewOsFs()}
+		path = filepath.Join(GinkgoT().TempDir(), "iris.cpf")
+		Expect(os.WriteFile(path, []byte("ZSTU=0\n"), 0660)).To(Succeed())
+		Expect(os.Chown(path, 12345, 12345)).To(Succeed())
+	})
+	AfterEach(func() {
+		if origFS != nil {
+			FS = origFS
+		}
+	})
+
+	It("overwrites the CPF in place so it keeps its owner", func() {
+		before, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ToggleZSTU(path, true)).To(BeFalse())
+		Expect(os.ReadFile(path)).To(WithTransform(toStr, Equal("ZSTU=1\n")))
+		after, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.SameFile(before, after)).To(BeTrue())
+		Expect(after.Mode()).To(Equal(before.Mode()))
+		entries, err := os.ReadDir(filepath.Dir(path))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+})
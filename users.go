@@ -0,0 +1,81 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	securityUsersCode = `MAIN
+ set rs=##class(%ResultSet).%New("Security.Users:List")
+ set sc=rs.Execute("*")
+ if 'sc write "ERROR:",$system.Status.GetErrorText(sc),! quit
+ while rs.Next() {
+ set name=rs.Get("Name")
+ kill props
+ set sc=##class(Security.Users).Get(name,.props)
+ if 'sc write "ERROR:",$system.Status.GetErrorText(sc),! quit
+ write "USER:",name,$char(9),+$get(props("Enabled")),$char(9),$get(props("Roles")),!
+ }
+ quit
+
+`
+)
+
+var (
+	securityUserRegexp = regexp.MustCompile(`(?m)^USER:([^\t\r\n]*)\t(\d+)\t([^\t\r\n]*)\r?$`)
+)
+
+// SecurityUser describes a user configured in an instance's security database
+type SecurityUser struct {
+	// Name is the username
+	Name string `json:"name"`
+	// Enabled is whether the user is able to log in
+	Enabled bool `json:"enabled"`
+	// Roles are the roles granted to the user
+	Roles []string `json:"roles"`
+}
+
+// SecurityUsers will query the running instance for the users in its security database.
+// Passwords are not retrievable and are not included.
+// It returns the users in the order they are listed by the instance and any error encountered.
+func (i *Instance) SecurityUsers() ([]SecurityUser, error) {
+	out, err := i.ExecuteString(systemNamespace, securityUsersCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configOutputError(out); err != nil {
+		return nil, fmt.Errorf("unable to read security users: %w", err)
+	}
+
+	users := make([]SecurityUser, 0)
+	for _, m := range securityUserRegexp.FindAllStringSubmatch(out, -1) {
+		roles := make([]string, 0)
+		for _, role := range strings.Split(m[3], ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
+		users = append(users, SecurityUser{Name: m[1], Enabled: m[2] != "0", Roles: roles})
+	}
+
+	return users, nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("SecurityUsers", func() {
+	var instance *Instance
+
+	// The fake session successfully imports the code and writes the provided output when it is run
+	writeSession := func(output string) {
+		instance.SessionPath = WriteFakeSession(FakeSession{Main: "printf '" + output + "'"})
+	}
+
+	BeforeEach(func() {
+		SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+		instance = &Instance{Name: "INSTTEST"}
+	})
+	AfterEach(func() {
+		SetExecuteTemporaryDirectory("")
+	})
+
+	It("Returns the users and their roles", func() {
+		writeSession(`USER:_SYSTEM\t1\t%%All\nUSER:app\t0\tAppUser,%%DB_APP\nUSER:nobody\t1\t\n`)
+		Expect(instance.SecurityUsers()).To(Equal([]SecurityUser{
+			{Name: "_SYSTEM", Enabled: true, Roles: []string{"%All"}},
+			{Name: "app", Enabled: false, Roles: []string{"AppUser", "%DB_APP"}},
+			{Name: "nobody", Enabled: true, Roles: []string{}},
+		}))
+	})
+
+	It("Returns an error reported by the instance", func() {
+		writeSession(`ERROR:#921: Insufficient privilege\n`)
+		_, err := instance.SecurityUsers()
+		Expect(err).To(MatchError(ContainSubstring("#921: Insufficient privilege")))
+	})
+})
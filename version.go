@@ -0,0 +1,188 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidVersion is an error signifying that a version string could not be parsed
+	ErrInvalidVersion = errors.New("invalid version")
+	// ErrIncompatibleDataVersion is an error signifying that one of the instance's databases was last used by a newer
+	// version or a different product than the instance's binaries
+	ErrIncompatibleDataVersion = errors.New("data version is incompatible with the instance version")
+	// ErrIncompatibleCPFVersion is an error signifying that the instance's CPF was last written by a newer version or
+	// a different product than the instance's binaries
+	ErrIncompatibleCPFVersion = errors.New("CPF version is incompatible with the instance version")
+)
+
+// Version represents the components of an instance's version (e.g. 2018.1.1.643.0)
+type Version struct {
+	Major int
+	Minor int
+	Point int
+	Build int
+	// The version string the components were parsed from
+	Raw string
+}
+
+// ParseVersion parses a version string as reported by qlist into its components.
+// Only the major, minor, point and build components are parsed, any further components (e.g. the 0.16216 of the
+// legacy 2015.2.2.805.0.16216 format) are ignored.  Missing components (e.g. 2008.1) are treated as 0.
+// It returns the version and any error encountered.
+func ParseVersion(raw string) (Version, error) {
+	v := Version{Raw: raw}
+	parts := strings.Split(strings.TrimSpace(raw), ".")
+	components := []*int{&v.Major, &v.Minor, &v.Point, &v.Build}
+	for n, component := range components {
+		if n >= len(parts) {
+			break
+		}
+
+		c, err := strconv.Atoi(parts[n])
+		if err != nil || c < 0 {
+			return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, raw)
+		}
+		*component = c
+	}
+
+	return v, nil
+}
+
+// ParsedVersion parses the instance's version (see Version) into its components (see ParseVersion).
+// It returns the version and any error encountered.
+func (i *Instance) ParsedVersion() (Version, error) {
+	return ParseVersion(i.Version)
+}
+
+// Compare compares the version to the other version component by component.
+// It returns -1 if the version is older than other, 0 if they are the same and 1 if the version is newer than other.
+func (v Version) Compare(other Version) int {
+	if c := cmp.Compare(v.Major, other.Major); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(v.Point, other.Point); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(v.Build, other.Build)
+}
+
+// String returns the version as major.minor.point.build
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Point, v.Build)
+}
+
+// VerifyVersionCompatibility will ensure the instance's binaries can run its data (e.g. after copying DAT files between
+// hosts) by comparing the instance's version and product with those recorded in the header of each of its existing
+// databases' DAT files (see DatVersion).  Data from an older version is upgraded when the instance starts but data from
+// a newer version, or IRIS data with Caché/Ensemble binaries, prevents it from starting.
+// Only the major and minor versions are compared.
+// It returns an error wrapping ErrIncompatibleDataVersion naming the first incompatible database or any other error
+// encountered.
+func (i *Instance) VerifyVersionCompatibility() error {
+	binary, err := i.ParsedVersion()
+	if err != nil {
+		return err
+	}
+
+	dbs, err := i.Databases()
+	if err != nil {
+		return err
+	}
+
+	for _, db := range dbs {
+		if !db.Exists {
+			continue
+		}
+
+		product, raw, err := readDatHeader(db.DatPath)
+		if err != nil {
+			return err
+		}
+
+		if product == Iris && i.Product != Iris {
+			return fmt.Errorf("%w, database: %s, data product: %s, instance: %s", ErrIncompatibleDataVersion, db.Name, product, i.Name)
+		}
+
+		recorded, err := ParseVersion(raw)
+		if err != nil {
+			return err
+		}
+
+		if newerRelease(recorded, binary) {
+			return fmt.Errorf("%w, database: %s, data version: %s, instance version: %s, instance: %s", ErrIncompatibleDataVersion, db.Name, raw, i.Version, i.Name)
+		}
+	}
+
+	return nil
+}
+
+// newerRelease reports whether the major and minor version of v is newer than that of other
+func newerRelease(v, other Version) bool {
+	return v.Major > other.Major || (v.Major == other.Major && v.Minor > other.Minor)
+}
+
+// VerifyCPFVersionCompatibility will ensure the instance's binaries can run with the CPF kept with its data by
+// comparing the version and product recorded in the [ConfigFile] section of the CPF with the instance's.  Unlike
+// VerifyVersionCompatibility it does not read the databases so it can be used when they are not yet in place.
+// A CPF from an older version is upgraded when the instance starts but a CPF from a newer version, or an IRIS CPF with
+// Caché/Ensemble binaries, prevents it from starting.
+// Only the major and minor versions are compared as those are all the CPF records.
+// It returns an error wrapping ErrIncompatibleCPFVersion when they are incompatible or any other error encountered.
+func (i *Instance) VerifyCPFVersionCompatibility() error {
+	binary, err := i.ParsedVersion()
+	if err != nil {
+		return err
+	}
+
+	cpf, err := i.LoadCPF()
+	if err != nil {
+		return err
+	}
+
+	config := cpf.Section("ConfigFile")
+	if strings.EqualFold(config["Product"], "IRIS") && i.Product != Iris {
+		return fmt.Errorf("%w, CPF product: %s, instance: %s", ErrIncompatibleCPFVersion, config["Product"], i.Name)
+	}
+
+	raw, ok := config["Version"]
+	if !ok {
+		return fmt.Errorf("%w: the CPF does not record a version, cpf: %s", ErrInvalidVersion, i.CPFFilePath())
+	}
+
+	recorded, err := ParseVersion(raw)
+	if err != nil {
+		return err
+	}
+
+	if newerRelease(recorded, binary) {
+		return fmt.Errorf("%w, CPF version: %s, instance version: %s, instance: %s", ErrIncompatibleCPFVersion, raw, i.Version, i.Name)
+	}
+
+	return nil
+}
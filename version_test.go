@@ -0,0 +1,177 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+	"github.com/spf13/afero"
+)
+
+var _ = Describe("Version", func() {
+	DescribeTable("ParseVersion",
+		func(raw string, expected Version) {
+			expected.Raw = raw
+			Expect(ParseVersion(raw)).To(Equal(expected))
+		},
+		Entry("legacy format", "2015.2.2.805.0.16216", Version{Major: 2015, Minor: 2, Point: 2, Build: 805}),
+		Entry("older legacy format", "2012.2.3.903.2.12515", Version{Major: 2012, Minor: 2, Point: 3, Build: 903}),
+		Entry("current format", "2018.1.1.643.0", Version{Major: 2018, Minor: 1, Point: 1, Build: 643}),
+		Entry("short format", "2008.1", Version{Major: 2008, Minor: 1}),
+	)
+
+	DescribeTable("Invalid versions",
+		func(raw string) {
+			_, err := ParseVersion(raw)
+			Expect(err).To(MatchError(ErrInvalidVersion))
+		},
+		Entry("empty", ""),
+		Entry("not numeric", "2018.x.1"),
+		Entry("negative", "2018.-1"),
+	)
+
+	It("Parses the instance's version", func() {
+		instance := &Instance{Version: "2018.1.1.643.0"}
+		v, err := instance.ParsedVersion()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.String()).To(Equal("2018.1.1.643"))
+	})
+
+	DescribeTable("Compare",
+		func(a, b string, expected int) {
+			va, err := ParseVersion(a)
+			Expect(err).NotTo(HaveOccurred())
+			vb, err := ParseVersion(b)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(va.Compare(vb)).To(Equal(expected))
+		},
+		Entry("equal", "2018.1.1.643.0", "2018.1.1.643.0", 0),
+		Entry("equal ignoring extra components", "2015.2.2.805.0.16216", "2015.2.2.805", 0),
+		Entry("older major", "2015.2.2.805.0.16216", "2016.2", -1),
+		Entry("newer minor", "2016.2.0.736.0", "2016.1.3.306.0", 1),
+		Entry("older point", "2018.1.1.643.0", "2018.1.2.309.0", -1),
+		Entry("newer build", "2018.1.1.643.0", "2018.1.1.600.0", 1),
+	)
+
+	Describe("VerifyVersionCompatibility", func() {
+		var (
+			instance *Instance
+			origFS   afero.Fs
+		)
+
+		// writeDatabases configures a database for each version string with a DAT recording it in its header, an empty
+		// version string configures a database whose DAT does not exist
+		writeDatabases := func(versions ...string) {
+			cpf := "[ConfigFile]\nProduct=IRIS\nVersion=2022.1\n\n[Databases]\n"
+			for n, version := range versions {
+				dir := filepath.Join(instance.DataDirectory, "db", string(rune('A'+n)))
+				Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+				cpf += string(rune('A'+n)) + "=" + dir + "/\n"
+				if version != "" {
+					dat := append(make([]byte, 512), []byte(version+"\x00")...)
+					Expect(os.WriteFile(filepath.Join(dir, instance.DetermineISCDatFileName()), dat, 0644)).To(Succeed())
+				}
+			}
+			Expect(os.WriteFile(instance.CPFFilePath(), []byte(cpf), 0644)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			origFS = FS
+			FS = afero.NewOsFs()
+			instance = &Instance{Name: "INSTTEST", Product: Iris, Version: "2022.1.2.574.0", DataDirectory: GinkgoT().TempDir(), CPFFileName: "iris.cpf"}
+		})
+		AfterEach(func() {
+			FS = origFS
+		})
+
+		DescribeTable("Compares the version of each database",
+			func(version string, compatible bool) {
+				writeDatabases("IRIS for UNIX (Ubuntu Server LTS for x86-64) 2022.1.2 (Build 574U)", "", version)
+				if compatible {
+					Expect(instance.VerifyVersionCompatibility()).To(Succeed())
+				} else {
+					Expect(instance.VerifyVersionCompatibility()).To(MatchError(ErrIncompatibleDataVersion))
+				}
+			},
+			Entry("same version", "IRIS for UNIX (Ubuntu Server LTS for x86-64) 2022.1.0 (Build 209U)", true),
+			Entry("older data", "IRIS for UNIX (Ubuntu Server LTS for x86-64) 2021.1.2 (Build 338U)", true),
+			Entry("newer minor", "IRIS for UNIX (Ubuntu Server LTS for x86-64) 2022.2.0 (Build 368U)", false),
+			Entry("newer major", "IRIS for UNIX (Ubuntu Server LTS for x86-64) 2023.1.0 (Build 229U)", false),
+		)
+
+		It("Rejects IRIS data with Caché binaries", func() {
+			instance.Product = Cache
+			instance.Version = "2018.1.4.505.1"
+			writeDatabases("IRIS for UNIX (Ubuntu Server LTS for x86-64) 2018.1.4 (Build 505U)")
+			Expect(instance.VerifyVersionCompatibility()).To(MatchError(ErrIncompatibleDataVersion))
+		})
+
+		It("Accepts Caché data with IRIS binaries", func() {
+			writeDatabases("Cache for UNIX (Red Hat Enterprise Linux for x86-64) 2018.1.4 (Build 505_1U)")
+			Expect(instance.VerifyVersionCompatibility()).To(Succeed())
+		})
+
+		It("Returns an error when a DAT header does not record a version", func() {
+			writeDatabases("not a database")
+			Expect(instance.VerifyVersionCompatibility()).To(MatchError(ErrDatVersionNotFound))
+		})
+	})
+
+	Describe("VerifyCPFVersionCompatibility", func() {
+		var instance *Instance
+
+		writeCPF := func(product, version string) {
+			cpf := "[ConfigFile]\nProduct=" + product + "\nVersion=" + version + "\n"
+			Expect(os.WriteFile(instance.CPFFilePath(), []byte(cpf), 0644)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			instance = &Instance{Name: "INSTTEST", Product: Iris, Version: "2022.1.2.574.0", DataDirectory: GinkgoT().TempDir(), CPFFileName: "iris.cpf"}
+		})
+
+		DescribeTable("Compares the CPF version",
+			func(product, version string, compatible bool) {
+				writeCPF(product, version)
+				if compatible {
+					Expect(instance.VerifyCPFVersionCompatibility()).To(Succeed())
+				} else {
+					Expect(instance.VerifyCPFVersionCompatibility()).To(MatchError(ErrIncompatibleCPFVersion))
+				}
+			},
+			Entry("same version", "IRIS", "2022.1", true),
+			Entry("older CPF", "IRIS", "2021.1", true),
+			Entry("newer minor", "IRIS", "2022.2", false),
+			Entry("newer major", "IRIS", "2023.1", false),
+		)
+
+		It("Rejects an IRIS CPF with Caché binaries", func() {
+			instance.Product = Cache
+			instance.Version = "2018.1.1.643.0"
+			writeCPF("IRIS", "2018.1")
+			Expect(instance.VerifyCPFVersionCompatibility()).To(MatchError(ErrIncompatibleCPFVersion))
+		})
+
+		It("Returns an error when the CPF does not record a version", func() {
+			Expect(os.WriteFile(filepath.Join(instance.DataDirectory, "iris.cpf"), []byte("[ConfigFile]\nProduct=IRIS\n"), 0644)).To(Succeed())
+			Expect(instance.VerifyCPFVersionCompatibility()).To(MatchError(ErrInvalidVersion))
+		})
+	})
+})
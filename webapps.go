@@ -0,0 +1,77 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Web applications are the security applications named by their URL path, the others are routine and client
+	// applications
+	webApplicationsCode = `MAIN
+ set rs=##class(%ResultSet).%New("Security.Applications:List")
+ set sc=rs.Execute("*")
+ if 'sc write "ERROR:",$system.Status.GetErrorText(sc),! quit
+ while rs.Next() {
+ set name=rs.Get("Name")
+ if $extract(name)'="/" continue
+ kill props
+ set sc=##class(Security.Applications).Get(name,.props)
+ if 'sc write "ERROR:",$system.Status.GetErrorText(sc),! quit
+ write "WEBAPP:",name,$char(9),$get(props("NameSpace")),$char(9),+$get(props("Enabled")),!
+ }
+ quit
+
+`
+)
+
+var (
+	webAppRegexp = regexp.MustCompile(`(?m)^WEBAPP:([^\t\r\n]*)\t([^\t\r\n]*)\t(\d+)\r?$`)
+)
+
+// WebApp describes a CSP/web application configured in an instance
+type WebApp struct {
+	// Path is the URL path of the application (e.g. /csp/user)
+	Path string `json:"path"`
+	// Namespace is the namespace the application runs in
+	Namespace string `json:"namespace"`
+	// Enabled is whether the application accepts requests
+	Enabled bool `json:"enabled"`
+}
+
+// WebApplications will query the running instance for its configured CSP/web applications.
+// It returns the web applications in the order they are configured and any error encountered.
+func (i *Instance) WebApplications() ([]WebApp, error) {
+	out, err := i.ExecuteString(systemNamespace, webApplicationsCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configOutputError(out); err != nil {
+		return nil, fmt.Errorf("unable to read web applications: %w", err)
+	}
+
+	apps := make([]WebApp, 0)
+	for _, m := range webAppRegexp.FindAllStringSubmatch(out, -1) {
+		apps = append(apps, WebApp{Path: m[1], Namespace: strings.TrimSpace(m[2]), Enabled: m[3] != "0"})
+	}
+
+	return apps, nil
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2026 Ontario Systems
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package isclib_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/ontariosystems/isclib/v2"
+)
+
+var _ = Describe("WebApplications", func() {
+	var instance *Instance
+
+	// The fake session successfully imports the code and writes the provided output when it is run
+	writeSession := func(output string) {
+		instance.SessionPath = WriteFakeSession(FakeSession{Main: "printf '" + output + "'"})
+	}
+
+	BeforeEach(func() {
+		SetExecuteTemporaryDirectory(GinkgoT().TempDir())
+		instance = &Instance{Name: "INSTTEST"}
+	})
+	AfterEach(func() {
+		SetExecuteTemporaryDirectory("")
+	})
+
+	It("Returns the configured web applications", func() {
+		writeSession(`WEBAPP:/csp/sys\t%%SYS\t1\nWEBAPP:/csp/user\tUSER\t0\n`)
+		Expect(instance.WebApplications()).To(Equal([]WebApp{
+			{Path: "/csp/sys", Namespace: "%SYS", Enabled: true},
+			{Path: "/csp/user", Namespace: "USER", Enabled: false},
+		}))
+	})
+
+	It("Returns an empty slice when no web applications are configured", func() {
+		writeSession(``)
+		apps, err := instance.WebApplications()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apps).To(BeEmpty())
+	})
+
+	It("Returns an error reported by the instance", func() {
+		writeSession(`ERROR:#921: Insufficient privilege\n`)
+		_, err := instance.WebApplications()
+		Expect(err).To(MatchError(ContainSubstring("#921: Insufficient privilege")))
+	})
+})